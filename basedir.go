@@ -0,0 +1,43 @@
+package configor
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WithBaseDir resolve relative paths of configuration files against dir instead of the working directory
+func WithBaseDir(dir string) Option {
+	return func(configor *Configor) {
+		configor.baseDir = dir
+	}
+}
+
+// WithExecutableDir resolve relative paths of configuration files against the directory of the executable, so configurations
+// could be found no matter which working directory the process is launched in. Paths are kept unchanged if the executable's path is unknown
+func WithExecutableDir() Option {
+	return func(configor *Configor) {
+		if executable, err := os.Executable(); err == nil {
+			if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+				executable = resolved
+			}
+			configor.baseDir = filepath.Dir(executable)
+		}
+	}
+}
+
+// resolvePaths joins relative paths of local files with the base dir
+func (configor *Configor) resolvePaths(files []string) []string {
+	results := make([]string, len(files))
+	for i, file := range files {
+		results[i] = configor.resolvePath(file)
+	}
+	return results
+}
+
+// resolvePath joins the relative path of a local file with the base dir
+func (configor *Configor) resolvePath(file string) string {
+	if configor.baseDir == "" || isURL(file) || filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(configor.baseDir, file)
+}
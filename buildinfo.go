@@ -0,0 +1,118 @@
+package configor
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// WithBuildInfo set blank fields from `-X` linker flags embedded in the build info, variables of the main package (or the package
+// set with WithBuildInfoPackage) are used, the variable name after the package path is used as the dot-separated path of the
+// field (case insensitive), e.g.
+//
+//	go build -ldflags "-X main.DB.Host=localhost"
+//
+// sets DB.Host, values are parsed like env, and overridden by files and env
+func WithBuildInfo(enable bool) Option {
+	return func(configor *Configor) {
+		configor.buildInfo = enable
+	}
+}
+
+// WithBuildInfoPackage set blank fields from `-X` linker flags like WithBuildInfo, with variables of the package path only,
+// e.g. `-X myapp/config.DB.Host=localhost` for myapp/config, so variables of other packages don't override fields
+func WithBuildInfoPackage(pkg string) Option {
+	return func(configor *Configor) {
+		configor.buildInfo, configor.buildInfoPackage = true, pkg
+	}
+}
+
+// loadBuildInfo sets blank fields of config from `-X` linker flags of the package in the build info
+func loadBuildInfo(config interface{}, pkg string) error {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	for _, setting := range info.Settings {
+		if setting.Key == "-ldflags" {
+			if err := applyLinkerVariables(config, setting.Value, pkg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// applyLinkerVariables sets blank fields of config from `-X` flags in ldflags whose variables are in the package, pkg is
+// main if blank
+func applyLinkerVariables(config interface{}, ldflags string, pkg string) error {
+	if pkg == "" {
+		pkg = "main"
+	}
+
+	for _, variable := range parseLinkerVariables(ldflags) {
+		name, value, ok := strings.Cut(variable, "=")
+		if !ok {
+			continue
+		}
+
+		// match the full package path, e.g. myapp/config.DB.Host => DB.Host for myapp/config
+		fieldPath := strings.TrimPrefix(name, pkg+".")
+		if fieldPath == name || fieldPath == "" {
+			continue
+		}
+
+		field, err := Field(config, fieldPath)
+		if err != nil || !field.CanSet() || !isBlank(field) {
+			continue
+		}
+		if err := setValue(field, value); err != nil {
+			return &ConfigError{Field: fieldPath, Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+// parseLinkerVariables returns values of `-X` flags in ldflags, quoted values are unquoted
+func parseLinkerVariables(ldflags string) []string {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	var inArg bool
+	for _, r := range ldflags {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	var variables []string
+	for i := 0; i < len(args); i++ {
+		switch arg := strings.TrimPrefix(args[i], "-"); {
+		case arg == "-X" || arg == "X":
+			if i+1 < len(args) {
+				variables = append(variables, args[i+1])
+				i++
+			}
+		case strings.HasPrefix(arg, "X="), strings.HasPrefix(arg, "-X="):
+			variables = append(variables, arg[strings.Index(arg, "=")+1:])
+		}
+	}
+	return variables
+}
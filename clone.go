@@ -0,0 +1,88 @@
+package configor
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Clone returns a deep copy of src with the same type, it's copied with reflection so values not serializable
+// (e.g. *regexp.Regexp, func) are supported, funcs, channels and pointers to opaque structs (structs without exported fields
+// like *regexp.Regexp, *time.Location) are shared with src
+func Clone(src interface{}) (interface{}, error) {
+	if src == nil {
+		return nil, errors.New("invalid config, should not be nil")
+	}
+
+	srcValue := reflect.ValueOf(src)
+	dst := reflect.New(srcValue.Type()).Elem()
+	deepCopy(dst, srcValue)
+	return dst.Interface(), nil
+}
+
+func deepCopy(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+
+		if elem := src.Type().Elem(); elem.Kind() == reflect.Struct && !hasExportedFields(elem) {
+			dst.Set(src)
+			return
+		}
+
+		dst.Set(reflect.New(src.Type().Elem()))
+		deepCopy(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+
+		value := reflect.New(src.Elem().Type()).Elem()
+		deepCopy(value, src.Elem())
+		dst.Set(value)
+	case reflect.Struct:
+		// copy unexported fields shallowly, then copy exported fields deeply
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if src.Type().Field(i).PkgPath == "" {
+				deepCopy(dst.Field(i), src.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			deepCopy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for _, key := range src.MapKeys() {
+			value := reflect.New(src.Type().Elem()).Elem()
+			deepCopy(value, src.MapIndex(key))
+			dst.SetMapIndex(key, value)
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+func hasExportedFields(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
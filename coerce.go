@@ -0,0 +1,55 @@
+package configor
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// WithTypeCoercion parse values from env, default tags and secret resolvers with strconv for bool, integer and float fields
+// before falling back to YAML, e.g. `1` or `T` for a bool field, `0x1F` for an integer field
+func WithTypeCoercion(enable bool) Option {
+	return func(configor *Configor) {
+		configor.typeCoercion = enable
+	}
+}
+
+// parseValue parses value into field like setValue, with digit separators stripped and types coerced if enabled
+func (configor *Configor) parseValue(field reflect.Value, value string) error {
+	value = configor.stripDigitSeparator(field, value)
+	if configor.typeCoercion && coerceValue(field, value) {
+		return nil
+	}
+	return setValue(field, value)
+}
+
+// coerceValue parses value with strconv into bool, integer and float fields without registered converters, returns false
+// if the field isn't set
+func coerceValue(field reflect.Value, value string) bool {
+	if _, ok := getConverter(field.Type()); ok {
+		return false
+	}
+
+	switch field.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			field.SetBool(b)
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(value, 0, field.Type().Bits()); err == nil {
+			field.SetInt(n)
+			return true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(value, 0, field.Type().Bits()); err == nil {
+			field.SetUint(n)
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(value, field.Type().Bits()); err == nil {
+			field.SetFloat(f)
+			return true
+		}
+	}
+	return false
+}
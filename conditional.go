@@ -0,0 +1,67 @@
+package configor
+
+import "os"
+
+// ConditionalFile is an optional file loaded only when Condition returns true and Env is set to a non-blank value, e.g. files for
+// Docker or Kubernetes, it's always loaded if both are blank. Env is looked up like envs of fields, including the env file
+type ConditionalFile struct {
+	Path      string
+	Condition func() bool
+	Env       string
+}
+
+// WithConditionalFile load the optional file only when predicate returns true, it has higher priority than files passed to Load,
+// and it's skipped if not found
+func WithConditionalFile(predicate func() bool, file string) Option {
+	return func(configor *Configor) {
+		configor.conditionalFiles = append(configor.conditionalFiles, ConditionalFile{Path: file, Condition: predicate})
+	}
+}
+
+// WithEnvConditionalFile load the optional file only when env is set to a non-blank value, in the process env or the env file,
+// like WithConditionalFile. e.g. load local overrides for developers:
+//
+//	configor.New(configor.WithEnvConditionalFile("DEV", "config.local.yml")).Load(&Config, "config.yml")
+func WithEnvConditionalFile(env string, file string) Option {
+	return func(configor *Configor) {
+		configor.conditionalFiles = append(configor.conditionalFiles, ConditionalFile{Path: file, Env: env})
+	}
+}
+
+// WithConditionalFiles load conditional files whose Condition returns true like WithConditionalFile, earlier files have higher priority
+func WithConditionalFiles(files ...ConditionalFile) Option {
+	return func(configor *Configor) {
+		configor.conditionalFiles = append(configor.conditionalFiles, files...)
+	}
+}
+
+// EnvSet returns a predicate which is true if the env is set to a non-blank value in the process env, the env file isn't read
+// as the predicate doesn't know the Configor, use WithEnvConditionalFile or ConditionalFile.Env for that
+func EnvSet(name string) func() bool {
+	return func() bool {
+		return os.Getenv(name) != ""
+	}
+}
+
+// addConditionalFiles adds conditional files whose predicate holds and exist to sources with the highest priority
+func (configor *Configor) addConditionalFiles(sources []Source) []Source {
+	var matched []Source
+	for _, conditional := range configor.conditionalFiles {
+		if conditional.Condition != nil && !conditional.Condition() {
+			continue
+		}
+		if conditional.Env != "" && configor.getenv(conditional.Env) == "" {
+			continue
+		}
+
+		file := configor.resolvePath(conditional.Path)
+		if fileInfo, err := os.Stat(file); err == nil && fileInfo.Mode().IsRegular() {
+			matched = append(matched, FileSource(file))
+		}
+	}
+
+	if configor.filePrecedence == LastFileWins {
+		return append(sources, matched...)
+	}
+	return append(matched, sources...)
+}
@@ -1,42 +1,301 @@
 package configor
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/BurntSushi/toml"
 
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 )
 
+// Configor loads configurations with its options
+type Configor struct {
+	prefixes           []string
+	watchDebounce      time.Duration
+	watchMinInterval   time.Duration
+	watchLocker        sync.Locker
+	autoReloadInterval time.Duration
+	autoReloadFunc     func(file string) (string, error)
+	sourcePollInterval time.Duration
+	profile            string
+	requiredFields     map[string]bool
+	timeLayout         string
+	buildInfo          bool
+	buildInfoPackage   string
+	normalizeKeys      bool
+	ignoreEnvErrors    bool
+	degrade            bool
+	filePrecedence     FilePrecedence
+	secretResolvers    []secretResolver
+	httpHeader         http.Header
+	httpClient         *http.Client
+	httpTimeout        time.Duration
+	precedence         ConfigPrecedence
+	envNameFunc        func(path []string) string
+	namingConvention   NamingConvention
+	envVarSeparator    string
+	collectErrors      bool
+	yamlStyle          yamlv3.Style
+	jsonPrefix         string
+	jsonIndent         string
+	jsonSortKeys       bool
+	digitSeparator     string
+	describeFormat     string
+	explicitEnvOnly    bool
+	conditionalFiles   []ConditionalFile
+	lookupEnvFunc      func(name string) (string, bool)
+	environFunc        func() []string
+	envFile            string
+	baseDir            string
+	logger             Logger
+	maxDepth           int
+	minVersion         string
+	versionKey         string
+	envSources         []EnvSource
+	envParents         map[string]string
+	rangeCheck         bool
+	typeCoercion       bool
+	redactSecrets      bool
+	verbose            bool
+	fieldTransformer   func(path, value string) string
+	maxSliceIndex      int
+	checkFiles         bool
+	fileEnvSuffix      string
+
+	// state is shared with copies of the Configor
+	state *loadState
+}
+
+// loadState is the last loaded configuration of a Configor
+type loadState struct {
+	mutex  sync.RWMutex
+	config interface{}
+	keys   []string
+}
+
+// Option is used to customize a Configor
+type Option func(*Configor)
+
+// New initialize a Configor with options
+func New(opts ...Option) *Configor {
+	configor := &Configor{
+		watchDebounce: 100 * time.Millisecond,
+		lookupEnvFunc: os.LookupEnv,
+		environFunc:   environ,
+		httpHeader:    http.Header{},
+		httpTimeout:   30 * time.Second,
+		maxSliceIndex: 1024,
+		state:         &loadState{},
+	}
+	for _, opt := range opts {
+		opt(configor)
+	}
+	return configor
+}
+
+// WithPrefixes set env prefixes, they will be tried in order when reading env for a field, and the first one that's set will be used,
+// the first prefix is the primary one. Use "-" for blank prefix
+func WithPrefixes(prefixes ...string) Option {
+	return func(configor *Configor) {
+		configor.prefixes = prefixes
+	}
+}
+
+// WithEnvNameFunc set the function to generate env names from field paths, e.g. []string{"DB", "Name"} for field DB.Name,
+// prefixes are not used in this case, the `env` tag still takes precedence
+func WithEnvNameFunc(fn func(path []string) string) Option {
+	return func(configor *Configor) {
+		configor.envNameFunc = fn
+	}
+}
+
+// WithIgnoreEnvErrors skip envs failed to be parsed with a warning rather than returning an error
+func WithIgnoreEnvErrors(ignore bool) Option {
+	return func(configor *Configor) {
+		configor.ignoreEnvErrors = ignore
+	}
+}
+
+// FilePrecedence decides which file wins when loading multiple configuration files
+type FilePrecedence int
+
+const (
+	// FirstFileWins means earlier files have higher priority, it's the default
+	FirstFileWins FilePrecedence = iota
+	// LastFileWins means later files have higher priority, files are loaded from left to right
+	LastFileWins
+)
+
+// WithFilePrecedence set which file wins when loading multiple configuration files, default is FirstFileWins
+func WithFilePrecedence(precedence FilePrecedence) Option {
+	return func(configor *Configor) {
+		configor.filePrecedence = precedence
+	}
+}
+
+// WithExplicitEnvOnly only read env for fields tagged with `env`, env names are not generated for other fields, so internal fields
+// couldn't be overridden by guessed env names
+func WithExplicitEnvOnly(explicit bool) Option {
+	return func(configor *Configor) {
+		configor.explicitEnvOnly = explicit
+	}
+}
+
+// WithMaxDepth returns an error if nested structs are deeper than n levels, e.g. circular references, default is unlimited
+func WithMaxDepth(n int) Option {
+	return func(configor *Configor) {
+		configor.maxDepth = n
+	}
+}
+
+// WithFieldTransformer set the function to post-process values of string fields after they're loaded from any source,
+// called with the dot-separated path of fields, e.g. trim spaces or expand `~` to the home directory
+func WithFieldTransformer(transform func(path, value string) string) Option {
+	return func(configor *Configor) {
+		configor.fieldTransformer = transform
+	}
+}
+
+// WithGracefulDegradation treat missing files, envs failed to be parsed and non-required validation failures as warnings
+// rather than errors, use LoadWithWarnings to get them
+func WithGracefulDegradation(degrade bool) Option {
+	return func(configor *Configor) {
+		configor.degrade = degrade
+	}
+}
+
+// warn collects the non-fatal error into result
+func (configor *Configor) warn(result *LoadResult, err error) {
+	err = result.sanitize(err)
+	result.Warnings.Errors = append(result.Warnings.Errors, err)
+	configor.warnf("%v", err)
+}
+
+// Default is a sentinel env value, when a field's env is set to it, the env will be ignored and the `default` tag takes effect
+const Default = "__CONFIGOR_DEFAULT__"
+
+// Env is the environment of the application, set with env CONFIGOR_ENV
+type Env string
+
+const (
+	// Development is the default environment
+	Development Env = "development"
+	// Test is the environment when running go test
+	Test Env = "test"
+	// Production is the production environment
+	Production Env = "production"
+	// Staging is the staging environment
+	Staging Env = "staging"
+)
+
+// IsProduction returns true if the environment is production
+func (env Env) IsProduction() bool {
+	return env == Production
+}
+
+// IsDevelopment returns true if the environment is development
+func (env Env) IsDevelopment() bool {
+	return env == Development
+}
+
+// IsTest returns true if the environment is test
+func (env Env) IsTest() bool {
+	return env == Test
+}
+
 // ENV will return environment
-func ENV() string {
+func ENV() Env {
 	if env := os.Getenv("CONFIGOR_ENV"); env != "" {
-		return env
+		return Env(env)
 	}
 	// return test when running go test
-	if isTest, _ := regexp.MatchString("/_test/", os.Args[0]); isTest {
-		return "test"
+	if isTest, _ := regexp.MatchString("/_test/", os.Args[0]); isTest || strings.HasSuffix(strings.TrimSuffix(os.Args[0], ".exe"), ".test") {
+		return Test
 	}
-	return "development"
+	return Development
 }
 
-func getConfigurationWithENV(file, env string) (string, error) {
-	var envFile string
+// env returns environment like ENV, but CONFIGOR_ENV is looked up like envs of fields first, e.g. from the env file
+func (configor *Configor) env() Env {
+	if env := configor.getenv("CONFIGOR_ENV"); env != "" {
+		return Env(env)
+	}
+	return ENV()
+}
+
+// getenv returns the env looked up like envs of fields, falls back to the process env if it's not found, e.g. when envs are
+// read from a projected volume
+func (configor *Configor) getenv(name string) string {
+	if value, ok := configor.lookupEnvFunc(name); ok {
+		return value
+	}
+	return os.Getenv(name)
+}
+
+// getDefault returns the `default_<env>` tag of the field for current environment if set, otherwise the `default` tag
+func (configor *Configor) getDefault(fieldStruct reflect.StructField) string {
+	if value, ok := fieldStruct.Tag.Lookup("default_" + string(configor.env())); ok {
+		return value
+	}
+	return fieldStruct.Tag.Get("default")
+}
+
+// IsEnv returns true if the current environment is name
+func IsEnv(name string) bool {
+	return string(ENV()) == name
+}
+
+// Environments returns sorted environments which have configuration files for file, e.g. `production` for `config.production.yml`,
+// the example environment is excluded
+func Environments(file string) []string {
+	var extname = path.Ext(file)
+	var base = strings.TrimSuffix(file, extname)
+	matches, _ := filepath.Glob(base + ".*" + extname)
+
+	var envs []string
+	for _, match := range matches {
+		if fileInfo, err := os.Stat(match); err != nil || !fileInfo.Mode().IsRegular() {
+			continue
+		}
+
+		env := strings.TrimSuffix(strings.TrimPrefix(match, base+"."), extname)
+		if env != "" && env != "example" && !strings.Contains(env, ".") {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	return envs
+}
+
+func getFileWithENV(file, env string) string {
 	var extname = path.Ext(file)
 
 	if extname == "" {
-		envFile = fmt.Sprintf("%v.%v", file, env)
-	} else {
-		envFile = fmt.Sprintf("%v.%v%v", strings.TrimSuffix(file, extname), env, extname)
+		return fmt.Sprintf("%v.%v", file, env)
 	}
+	return fmt.Sprintf("%v.%v%v", strings.TrimSuffix(file, extname), env, extname)
+}
+
+func getConfigurationWithENV(file, env string) (string, error) {
+	var envFile = getFileWithENV(file, env)
 
 	if fileInfo, err := os.Stat(envFile); err == nil && fileInfo.Mode().IsRegular() {
 		return envFile, nil
@@ -44,13 +303,22 @@ func getConfigurationWithENV(file, env string) (string, error) {
 	return "", fmt.Errorf("failed to find file %v", file)
 }
 
-func getConfigurations(files ...string) ([]string, error) {
+// getConfigurations returns files to load, with errors of files not found, env files of parent environments are loaded
+// before the env file of current environment
+func (configor *Configor) getConfigurations(files ...string) ([]string, []error) {
 	var results []string
-	env := ENV()
+	var errs []error
+	envs, _ := configor.envChain(string(configor.env()))
 	for i := len(files) - 1; i >= 0; i-- {
 		var foundFile bool
 		var file = files[i]
 
+		// remote configuration
+		if isURL(file) {
+			results = append(results, file)
+			continue
+		}
+
 		// check configuration
 		if fileInfo, err := os.Stat(file); err == nil && fileInfo.Mode().IsRegular() {
 			foundFile = true
@@ -58,145 +326,706 @@ func getConfigurations(files ...string) ([]string, error) {
 		}
 
 		// check env configuration
-		if file, err := getConfigurationWithENV(file, env); err == nil {
-			foundFile = true
-			results = append(results, file)
+		for _, env := range envs {
+			if file, err := getConfigurationWithENV(file, env); err == nil {
+				foundFile = true
+				results = append(results, file)
+			}
 		}
 
 		// check example configuration
 		if !foundFile {
 			if example, err := getConfigurationWithENV(file, "example"); err == nil {
-				//fmt.Printf("Failed to find configuration %v, using example file %v\n", file, example)
 				results = append(results, example)
 			} else {
-				return nil, errors.New("Failed to find configuration " + file + "\n")
+				errs = append(errs, errors.New("Failed to find configuration "+file+"\n"))
 			}
 		}
 	}
-	return results, nil
+	return results, errs
 }
 
-func getPrefix(config interface{}) string {
-	if prefix := os.Getenv("CONFIGOR_ENV_PREFIX"); prefix != "" {
-		return prefix
+// expandGlobs resolves paths of file sources against the base dir, and replaces glob patterns with the sorted files matched,
+// patterns matched nothing are warned
+func (configor *Configor) expandGlobs(result *LoadResult, sources []Source) []Source {
+	var expanded []Source
+	for _, source := range sources {
+		fileSource, ok := source.(FileSource)
+		if !ok {
+			expanded = append(expanded, source)
+			continue
+		}
+
+		file := configor.resolvePath(string(fileSource))
+		if isURL(file) || !strings.ContainsAny(file, "*?[") {
+			expanded = append(expanded, FileSource(file))
+			continue
+		}
+
+		matches, err := filepath.Glob(file)
+		if err == nil && len(matches) == 0 {
+			err = errors.New("No configuration matches " + file)
+		}
+		if err != nil {
+			configor.warn(result, err)
+			continue
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			expanded = append(expanded, FileSource(match))
+		}
+	}
+	return expanded
+}
+
+// getPrefixes returns env prefixes, set with option WithPrefixes or env CONFIGOR_ENV_PREFIX (separated by comma),
+// "-" means blank prefix
+func (configor *Configor) getPrefixes() []string {
+	prefixes := configor.prefixes
+	if len(prefixes) == 0 {
+		if prefix := configor.getenv("CONFIGOR_ENV_PREFIX"); prefix != "" {
+			prefixes = strings.Split(prefix, ",")
+		} else {
+			prefixes = []string{"configor"}
+		}
+	}
+
+	var results []string
+	for _, prefix := range prefixes {
+		if prefix = strings.TrimSpace(prefix); prefix == "-" {
+			prefix = ""
+		}
+		results = append(results, prefix)
 	}
-	return "configor"
+	return results
+}
+
+// getFieldEnvNames returns env names of a field, the `env` tag will be used if set
+func (configor *Configor) getFieldEnvNames(fieldStruct reflect.StructField, names []string) []string {
+	if envName := fieldStruct.Tag.Get("env"); envName != "" {
+		return []string{envName}
+	}
+
+	if configor.explicitEnvOnly {
+		return nil
+	}
+	return configor.getEnvNames(names)
+}
+
+// getEnvName returns the env name of a field with the primary prefix, blank if the field couldn't be set from env
+func (configor *Configor) getEnvName(fieldStruct reflect.StructField, names []string) string {
+	if envNames := configor.getFieldEnvNames(fieldStruct, names); len(envNames) > 0 {
+		return envNames[0]
+	}
+	return ""
+}
+
+// ignorePrefix is the first name of fields nested in a field tagged with `ignore_prefix:"true"`, env prefixes are not added for them
+const ignorePrefix = "\x00ignore_prefix"
+
+// getEnvNames returns env names of a field for all prefixes
+func (configor *Configor) getEnvNames(names []string) []string {
+	if len(names) > 0 && names[0] == ignorePrefix {
+		names = names[1:]
+		if configor.envNameFunc != nil {
+			return []string{configor.envNameFunc(names)}
+		}
+		return []string{configor.formatEnvName(names)}
+	}
+
+	if configor.envNameFunc != nil {
+		return []string{configor.envNameFunc(names)}
+	}
+
+	var envNames []string
+	for _, prefix := range configor.getPrefixes() {
+		if prefix == "" {
+			envNames = append(envNames, configor.formatEnvName(names))
+		} else {
+			envNames = append(envNames, configor.formatEnvName(append([]string{prefix}, names...)))
+		}
+	}
+	return envNames
 }
 
 // Save will save the configurations to a file name you provide
 func Save(config interface{}, filename string) error {
-	var js []byte
-	var err error
+	return New().Save(config, filename)
+}
 
-	switch {
-	case strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml"):
-		js, err = yaml.Marshal(&config)
-	case strings.HasSuffix(filename, ".json"):
-		js, err = json.Marshal(&config)
+// Save will save the configurations to a file name you provide, the format is decided by its extension (see RegisterExtension)
+func (configor *Configor) Save(config interface{}, filename string) error {
+	js, err := configor.SaveBytes(config, fileFormat(filename))
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, js, 0600)
+}
+
+// SaveBytes will return the bytes that Save would write for the format (yaml, yml, toml or json) without touching disk
+func SaveBytes(config interface{}, format string) ([]byte, error) {
+	return New().SaveBytes(config, format)
+}
+
+// SaveBytes will return the bytes that Save would write for the format (yaml, yml, toml or json) without touching disk,
+// fields are saved in the order of their `order` tags if set
+func (configor *Configor) SaveBytes(config interface{}, format string) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := configor.encode(&buffer, config, format); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// encode writes config serialized in the format (yaml, yml, toml or json) to w
+func (configor *Configor) encode(w io.Writer, config interface{}, format string) error {
+	config = orderFields(config)
+
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "yaml", "yml":
+		if configor.hasYAMLStyle(config) {
+			return configor.encodeYAML(w, config)
+		}
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(&config); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "toml":
+		return toml.NewEncoder(w).Encode(config)
+	case "json":
+		data, err := configor.marshalJSON(&config)
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		return err
 	default:
 		return errors.New("Unknown file type")
 	}
+}
+
+// Load will unmarshal configurations to struct from files that you provide
+func Load(config interface{}, files ...string) error {
+	return New().Load(config, files...)
+}
 
+// Load will unmarshal configurations to struct from files that you provide
+func (configor *Configor) Load(config interface{}, files ...string) error {
+	_, err := configor.LoadWithResult(config, files...)
+	return err
+}
+
+// LoadWithWarnings will load configurations like Load, and return non-fatal errors skipped, use it with WithGracefulDegradation
+func (configor *Configor) LoadWithWarnings(config interface{}, files ...string) (*Warnings, error) {
+	result, err := configor.LoadWithResult(config, files...)
+	return result.Warnings, err
+}
+
+// LoadWithResult will load configurations like Load, and return where values of fields come from
+func LoadWithResult(config interface{}, files ...string) (*LoadResult, error) {
+	return New().LoadWithResult(config, files...)
+}
+
+// LoadWithResult will load configurations like Load, and return where values of fields come from
+func (configor *Configor) LoadWithResult(config interface{}, files ...string) (*LoadResult, error) {
+	result, err := configor.loadConfig(config, fileSources(files)...)
 	if err != nil {
-		return nil
+		return result, err
 	}
 
-	err = ioutil.WriteFile(filename, js, 0600)
-	return err
+	configor.state.mutex.Lock()
+	configor.state.config, configor.state.keys = config, nil
+	configor.state.mutex.Unlock()
+	return result, nil
 }
 
-// Load will unmarshal configurations to struct from files that you provide
-func Load(config interface{}, files ...string) error {
-	files, err := getConfigurations(files...)
+// LoadPartial will load configurations like Load, but returns paths of required fields which are blank instead of failing
+func LoadPartial(config interface{}, files ...string) ([]string, error) {
+	return New().LoadPartial(config, files...)
+}
+
+// LoadPartial will load whatever configurations exist like Load with all errors collected, and returns paths of fields which are
+// required (by `required` tags or LoadRequiring paths) but blank instead of failing, e.g. to prompt for them in a setup wizard.
+// Other errors are returned as LoadErrors
+func (configor *Configor) LoadPartial(config interface{}, files ...string) ([]string, error) {
+	scoped := *configor
+	scoped.collectErrors = true
+
+	_, err := scoped.loadConfig(config, fileSources(files)...)
+	loadErrors, ok := err.(LoadErrors)
+	if !ok {
+		return nil, err
+	}
+
+	var missing []string
+	var errs LoadErrors
+	for _, err := range loadErrors {
+		var phaseError *PhaseError
+		if errors.As(err, &phaseError) && phaseError.Phase == PhaseRequired {
+			missing = append(missing, phaseError.Field)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return missing, errs
+	}
+	return missing, nil
+}
+
+// LoadWithDefaults will deep copy defaults into config, then load configurations from files and env over it like Load
+func LoadWithDefaults(defaults, config interface{}, files ...string) error {
+	return New().LoadWithDefaults(defaults, config, files...)
+}
+
+// LoadWithDefaults will deep copy defaults (a struct or pointer to struct of config's type) into config, then load configurations
+// from files and env over it like Load, it's useful for complex default values constructed in code
+func (configor *Configor) LoadWithDefaults(defaults, config interface{}, files ...string) error {
+	configValue := reflect.ValueOf(config)
+	defaultsValue := reflect.Indirect(reflect.ValueOf(defaults))
+	if configValue.Kind() != reflect.Ptr || !defaultsValue.IsValid() || defaultsValue.Type() != configValue.Elem().Type() {
+		return fmt.Errorf("invalid defaults %T, should be the type of config %T", defaults, config)
+	}
+
+	clone, err := Clone(defaultsValue.Interface())
 	if err != nil {
 		return err
 	}
-	for _, file := range files {
-		if err := load(config, file); err != nil {
-			return err
-		}
+	configValue.Elem().Set(reflect.ValueOf(clone))
+	return configor.Load(config, files...)
+}
+
+// LoadRequiring will load configurations like Load, with fields of requiredPaths required in addition to fields tagged with `required`,
+// paths are dot-separated, e.g. DB.Password, Contacts.Email (for all elements) or Contacts[0].Email
+func LoadRequiring(config interface{}, requiredPaths []string, files ...string) error {
+	return New().LoadRequiring(config, requiredPaths, files...)
+}
+
+// LoadRequiring will load configurations like Load, with fields of requiredPaths required in addition to fields tagged with `required`,
+// so the same struct could be reused with different required fields
+func (configor *Configor) LoadRequiring(config interface{}, requiredPaths []string, files ...string) error {
+	scoped := *configor
+	scoped.requiredFields = map[string]bool{}
+	for fieldPath, required := range configor.requiredFields {
+		scoped.requiredFields[fieldPath] = required
+	}
+	for _, fieldPath := range requiredPaths {
+		scoped.requiredFields[fieldPath] = true
+	}
+	return scoped.Load(config, files...)
+}
+
+func (configor *Configor) loadConfig(config interface{}, sources ...Source) (*LoadResult, error) {
+	result := &LoadResult{Sources: map[string]ValueSource{}, Warnings: &Warnings{}}
+
+	configor, sources, err := configor.withProfile(sources)
+	if err != nil {
+		return result, err
 	}
 
-	if prefix := getPrefix(config); prefix == "-" {
-		return processTags(config)
+	if configor, err = configor.withEnvFile(); err != nil {
+		return result, err
+	}
+
+	if _, err := configor.envChain(string(configor.env())); err != nil {
+		return result, err
+	}
+
+	if configor.precedence == PrecedenceEnvOnly {
+		sources = nil
 	} else {
-		return processTags(config, prefix)
+		sources = configor.addConditionalFiles(configor.expandGlobs(result, sources))
 	}
-}
+	sources = configor.addEnvSources(sources)
+
+	if configor.filePrecedence == LastFileWins {
+		reversed := make([]Source, len(sources))
+		for i, source := range sources {
+			reversed[len(sources)-1-i] = source
+		}
+		sources = reversed
+	}
+
+	// sources are loaded from right to left, so earlier sources have higher priority
+	sources, missingFiles := configor.getSources(sources)
+	for _, err := range missingFiles {
+		if configor.degrade {
+			configor.warn(result, err)
+		} else if err := configor.fail(result, &PhaseError{Phase: PhaseFile, Err: err}); err != nil {
+			return result, err
+		}
+	}
+	for _, source := range sources {
+		// fields tagged with `once:"true"` keep the value set by earlier loaded sources
+		once := onceValues(config)
+		err := configor.load(config, source, result)
+		restoreOnceValues(config, once)
+		if err != nil {
+			if err := configor.fail(result, &PhaseError{Phase: PhaseDecode, File: sourceName(source), Err: err}); err != nil {
+				return result, err
+			}
+			continue
+		}
+		configor.infof("Loaded configuration %v", sourceName(source))
 
-func processTags(config interface{}, prefix ...string) error {
-	configValue := reflect.Indirect(reflect.ValueOf(config))
-	if configValue.Kind() != reflect.Struct {
-		return errors.New("invalid config, should be struct")
+		// later files have higher priority
+		if file, ok := source.(FileSource); ok && !isURL(string(file)) {
+			result.File, result.Format = string(file), strings.TrimPrefix(fileFormat(string(file)), ".")
+		}
 	}
+	result.config, result.configor = config, configor
 
-	configType := configValue.Type()
-	for i := 0; i < configType.NumField(); i++ {
-		fieldStruct := configType.Field(i)
-		field := configValue.Field(i)
+	if configor.buildInfo {
+		if err := loadBuildInfo(config, configor.buildInfoPackage); err != nil {
+			if err := configor.fail(result, &PhaseError{Phase: PhaseDecode, Err: err}); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if configor.checkFiles {
+		if err := configor.CheckFiles(config); err != nil {
+			if err := configor.fail(result, &PhaseError{Phase: PhaseFile, Err: err}); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if err := configor.processTags(config, result, ""); err != nil {
+		return result, err
+	}
+
+	if err := configor.validate(config, result); err != nil {
+		return result, err
+	}
+
+	// derive computed fields only if loaded successfully
+	if len(result.errors) == 0 {
+		if err := configor.finalize(config, result); err != nil {
+			return result, err
+		}
+	}
+
+	if len(result.errors) > 0 {
+		return result, result.errors
+	}
+	return result, nil
+}
+
+func (configor *Configor) processTags(config interface{}, result *LoadResult, parentPath string, names ...string) error {
+	return walkFields(config, parentPath, names, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		// stop walking regardless of collecting errors, as fields of circular references are endless
+		if configor.maxDepth > 0 && fieldDepth(fieldPath) > configor.maxDepth {
+			return fmt.Errorf("%v exceeds the max depth %d", fieldPath, configor.maxDepth)
+		}
+
+		var source ValueSource
+		if !isBlank(field) {
+			source = ValueFromFile
+		}
+
+		secret := configor.isSecret(fieldStruct)
+		if secret {
+			defer func() {
+				if !isBlank(field) {
+					result.addSecret(formatValue(field))
+				}
+			}()
+		}
 
 		// read configuration from shell env
-		var envName = fieldStruct.Tag.Get("env")
-		if envName == "" {
-			envName = strings.ToUpper(strings.Join(append(prefix, fieldStruct.Name), "_"))
+		if fromEnv, err := configor.applyEnv(result, field, fieldStruct, fieldPath, fieldNames, secret); err != nil {
+			return err
+		} else if fromEnv {
+			source = ValueFromEnv
 		}
 
-		if envName != "" {
-			if value := os.Getenv(envName); value != "" {
-				if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
-					return err
+		// source:"env" requires the field from env only, source:"file" rejects env overrides
+		var sourceErr error
+		switch constraint := fieldStruct.Tag.Get("source"); {
+		case constraint == "env" && source != ValueFromEnv:
+			if !isBlank(field) {
+				sourceErr = errors.New(fieldPath + " should be set from env only, but set in files")
+			} else {
+				sourceErr = errors.New(fieldPath + " is required from env, but blank")
+			}
+		case constraint == "file" && source == ValueFromEnv:
+			sourceErr = errors.New(fieldPath + " should be set in files only, but set from env")
+		}
+		if sourceErr != nil {
+			return configor.fail(result, &PhaseError{Phase: PhaseEnv, Field: fieldPath, Err: sourceErr})
+		}
+
+		if err := configor.deprecate(result, field, fieldStruct, parent, fieldPath, source); err != nil {
+			return err
+		}
+
+		// resolve secrets referenced in tags if is blank
+		if isBlank(field) {
+			for _, resolver := range configor.resolvers() {
+				if ref := fieldStruct.Tag.Get(resolver.tag); ref != "" {
+					value, err := resolver.resolver.Resolve(ref)
+					if err == nil {
+						err = configor.parseValue(field, value)
+					}
+					if err != nil {
+						if err := configor.fail(result, &PhaseError{Phase: PhaseResolve, Field: fieldPath, Err: &ConfigError{Field: fieldPath, Value: ref, Err: err}}); err != nil {
+							return err
+						}
+						break
+					}
+					source = ValueFromResolver
+					break
 				}
 			}
 		}
 
-		if isBlank := reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()); isBlank {
+		if isBlank(field) {
 			// set default configuration if is blank
-			if value := fieldStruct.Tag.Get("default"); value != "" {
-				if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
-					return err
+			if value := configor.getDefault(fieldStruct); value != "" {
+				if secret {
+					result.addSecret(value)
+				}
+				if err := configor.parseValue(field, value); err != nil {
+					return configor.fail(result, &PhaseError{Phase: PhaseDefault, Field: fieldPath, Err: &ConfigError{Field: fieldPath, Value: value, Err: err}})
 				}
-			} else if fieldStruct.Tag.Get("required") == "true" {
+				source = ValueFromDefault
+			} else if configor.isRequired(fieldStruct, fieldPath) {
 				// set configuration has value if it is required
-				return errors.New(fieldStruct.Name + " is required, but blank")
+				return configor.fail(result, &PhaseError{Phase: PhaseRequired, Field: fieldPath, Err: requiredError(fieldStruct, fieldPath)})
+			}
+		} else if value := configor.getDefault(fieldStruct); value != "" && source == ValueFromFile {
+			// check if the value set in files equals the default value
+			defaultValue := reflect.New(field.Type()).Elem()
+			if configor.parseValue(defaultValue, value) == nil && reflect.DeepEqual(defaultValue.Interface(), field.Interface()) {
+				result.RedundantDefaults = append(result.RedundantDefaults, fieldPath)
 			}
 		}
 
-		for field.Kind() == reflect.Ptr {
-			field = field.Elem()
+		if configor.fieldTransformer != nil && field.Kind() == reflect.String {
+			field.SetString(configor.fieldTransformer(fieldPath, field.String()))
 		}
 
-		if field.Kind() == reflect.Struct {
-			if err := processTags(field.Addr().Interface(), append(prefix, fieldStruct.Name)...); err != nil {
-				return err
-			}
+		if source != "" && !isNestedStruct(field.Type()) {
+			result.Sources[fieldPath] = source
 		}
+		return nil
+	})
+}
 
-		if field.Kind() == reflect.Slice {
-			var length = field.Len()
-			for i := 0; i < length; i++ {
-				if reflect.Indirect(field.Index(i)).Kind() == reflect.Struct {
-					if err := processTags(field.Index(i).Addr().Interface(), append(prefix, fieldStruct.Name, fmt.Sprintf("%d", i))...); err != nil {
-						return err
-					}
-				}
+// applyEnv sets the field from its env if set, returns true if it's set from env. Invalid env values are skipped and the value
+// loaded before is kept. Elements of slices are set from env with indices after that, see applySliceEnv
+func (configor *Configor) applyEnv(result *LoadResult, field reflect.Value, fieldStruct reflect.StructField, fieldPath string, fieldNames []string, secret bool) (bool, error) {
+	// fields tagged with `once:"true"` are not overridden by env if set already
+	if fieldStruct.Tag.Get("once") == "true" && !isBlank(field) {
+		return false, nil
+	}
+
+	var envNames []string
+	if configor.shouldReadEnv(field) {
+		envNames = configor.getFieldEnvNames(fieldStruct, fieldNames)
+	}
+
+	var fromEnv bool
+	if fieldStruct.Tag.Get("env_presence") == "true" && field.Kind() == reflect.Bool {
+		// presence flag, env is set means true regardless of its value
+		if _, ok := configor.lookupEnv(envNames, true); ok {
+			field.SetBool(true)
+			return true, nil
+		}
+	} else if value, ok := configor.lookupEnv(envNames, false); ok && value != Default {
+		var err error
+		if fromEnv, err = configor.setEnv(result, field, fieldPath, value, fieldStruct.Tag.Get("env_format"), secret); err != nil {
+			return false, err
+		}
+	} else if file, ok := configor.lookupEnv(configor.fileEnvNames(envNames), false); ok {
+		// file envs reference files containing the value, e.g. CONFIGOR_DB_PASSWORD_FILE
+		value, err := readFileRef(file)
+		if err != nil {
+			return false, configor.fail(result, &PhaseError{Phase: PhaseEnv, Field: fieldPath, Err: &ConfigError{Field: fieldPath, Value: file, Err: err}})
+		}
+		if fromEnv, err = configor.setEnv(result, field, fieldPath, value, fieldStruct.Tag.Get("env_format"), true); err != nil {
+			return false, err
+		}
+	}
+
+	if field.Kind() == reflect.Slice && len(envNames) > 0 {
+		fromElements, err := configor.applySliceEnv(result, field, fieldPath, fieldNames, fieldStruct.Tag.Get("env_format"), secret)
+		return fromEnv || fromElements, err
+	}
+	return fromEnv, nil
+}
+
+// setEnv sets the field from the env value, returns true if it's set. The value is decoded with json if format is json (from the
+// `env_format` tag), otherwise parsed like YAML. Invalid env values are skipped and the value loaded before is kept
+func (configor *Configor) setEnv(result *LoadResult, field reflect.Value, fieldPath string, value string, format string, secret bool) (bool, error) {
+	if secret {
+		result.addSecret(value)
+	}
+
+	original := reflect.New(field.Type()).Elem()
+	original.Set(field)
+
+	var err error
+	if strings.EqualFold(format, "json") {
+		err = json.Unmarshal([]byte(value), field.Addr().Interface())
+	} else {
+		err = configor.parseValue(field, value)
+	}
+	if err != nil {
+		// skip the invalid env, keep the value loaded from files
+		field.Set(original)
+		if configor.ignoreEnvErrors || configor.degrade {
+			configor.warn(result, &ConfigError{Field: fieldPath, Value: value, Err: err})
+			return false, nil
+		}
+		return false, configor.fail(result, &PhaseError{Phase: PhaseEnv, Field: fieldPath, Err: &ConfigError{Field: fieldPath, Value: value, Err: err}})
+	}
+	return true, nil
+}
+
+// lookupEnv returns the value of the first env that's set, blank env will be skipped unless allowBlank
+func (configor *Configor) lookupEnv(envNames []string, allowBlank bool) (string, bool) {
+	for _, envName := range envNames {
+		if value, ok := configor.lookupEnvFunc(envName); ok && (allowBlank || value != "") {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+var (
+	locationType = reflect.TypeOf((*time.Location)(nil))
+	bytesType    = reflect.TypeOf([]byte(nil))
+)
+
+// setValue parses value from env or default tag into field, with the registered converter of its type if exists
+func setValue(field reflect.Value, value string) error {
+	if convert, ok := getConverter(field.Type()); ok {
+		converted, err := convert(value)
+		if err != nil {
+			return err
+		}
+		field.Set(converted.Convert(field.Type()))
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if convert, ok := getConverter(field.Type().Elem()); ok {
+			converted, err := convert(value)
+			if err != nil {
+				return err
 			}
+			ptr := reflect.New(field.Type().Elem())
+			ptr.Elem().Set(converted.Convert(field.Type().Elem()))
+			field.Set(ptr)
+			return nil
 		}
 	}
-	return nil
+	return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+}
+
+// decodeBytes decodes value with prefix hex: or base64:, otherwise returns its raw bytes
+func decodeBytes(value string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(value, "hex:"):
+		return hex.DecodeString(strings.TrimPrefix(value, "hex:"))
+	case strings.HasPrefix(value, "base64:"):
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(value, "base64:"))
+	default:
+		return []byte(value), nil
+	}
 }
 
-func load(config interface{}, file string) error {
+func joinPath(parentPath, name string) string {
+	if parentPath == "" {
+		return name
+	}
+	return parentPath + "." + name
+}
+
+// fieldDepth returns the nesting level of the field, fields of the config are level 1
+func fieldDepth(fieldPath string) int {
+	return strings.Count(indexRegexp.ReplaceAllString(fieldPath, ""), ".") + 1
+}
+
+// readFile returns content and format of the file or URL
+func (configor *Configor) readFile(file string) ([]byte, string, error) {
+	if isURL(file) {
+		return configor.fetch(file)
+	}
+
 	data, err := ioutil.ReadFile(file)
+	return data, fileFormat(file), err
+}
+
+func (configor *Configor) load(config interface{}, source Source, result *LoadResult) error {
+	data, format, err := configor.readSource(source)
 	if err != nil {
 		return err
 	}
 
-	switch {
-	case strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml"):
+	// inline files referenced with !include in yaml files
+	file, isFile := source.(FileSource)
+	if ext := strings.ToLower(format); (ext == ".yaml" || ext == ".yml") && isFile && !isURL(string(file)) && bytes.Contains(data, []byte("!include")) {
+		absFile, err := filepath.Abs(string(file))
+		if err != nil {
+			return err
+		}
+		if data, err = resolveIncludes(data, string(file), []string{absFile}); err != nil {
+			return err
+		}
+	}
+
+	if configor.minVersion != "" {
+		if data, format, err = configor.checkVersion(result, source, config, data, format); err != nil {
+			return err
+		}
+	}
+
+	if configor.rangeCheck {
+		generic, err := decodeNumbers(data, format)
+		if err != nil {
+			return err
+		}
+		if err := checkRanges(normalizeKeys(generic, reflect.TypeOf(config)), reflect.TypeOf(config), ""); err != nil {
+			return err
+		}
+	}
+
+	// decode through json to support interface fields with registered types
+	hasTypes, err := prepareTypes(config)
+	if err != nil {
+		return err
+	}
+
+	if configor.normalizeKeys || hasTypes {
+		return configor.decodeWithNormalizedKeys(config, data, format)
+	}
+
+	if configor.digitSeparator != "" || configor.timeLayout != "" {
+		if data, format, err = configor.transformData(config, data, format); err != nil {
+			return err
+		}
+	}
+
+	// TOML local datetimes are decoded in local time zone, while they're in UTC in other formats
+	if strings.EqualFold(strings.TrimPrefix(format, "."), "toml") {
+		return decodeTOML(config, data)
+	}
+	return Decode(config, data, format)
+}
+
+// Decode will unmarshal data to config with the format (yaml, yml, toml or json), the format will be detected if it's unknown
+func Decode(config interface{}, data []byte, format string) error {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "yaml", "yml":
 		return yaml.Unmarshal(data, config)
-	case strings.HasSuffix(file, ".toml"):
+	case "toml":
 		return toml.Unmarshal(data, config)
-	case strings.HasSuffix(file, ".json"):
+	case "json":
 		return json.Unmarshal(data, config)
 	default:
 		if toml.Unmarshal(data, config) != nil {
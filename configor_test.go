@@ -2,15 +2,32 @@ package configor_test
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
 
 	"github.com/jinzhu/configor"
+	"github.com/jinzhu/configor/testutil"
 	"github.com/BurntSushi/toml"
 )
 
@@ -113,6 +130,178 @@ func TestLoadTOMLConfigWithoutExtension(t *testing.T) {
 	}
 }
 
+func TestSaveBackTOMLConfig(t *testing.T) {
+	config := generateDefaultConfig()
+	if bytes, err := configor.SaveBytes(config, "toml"); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			ioutil.WriteFile(file.Name()+".toml", bytes, 0644)
+			defer os.Remove(file.Name() + ".toml")
+
+			var result Config
+			loadResult, err := configor.LoadWithResult(&result, file.Name()+".toml")
+			if err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if loadResult.File != file.Name()+".toml" || loadResult.Format != "toml" {
+				t.Errorf("loaded file and format should be tracked, but got %v, %v", loadResult.File, loadResult.Format)
+			}
+
+			result.APPName = "edited"
+			if err := loadResult.SaveBack(); err != nil {
+				t.Errorf("No error should happen when save back, but got %v", err)
+			}
+
+			var saved Config
+			if _, err := toml.DecodeFile(file.Name()+".toml", &saved); err != nil || saved.APPName != "edited" || saved.DB.Name != config.DB.Name {
+				t.Errorf("config should be saved back as toml, but got %#v, %v", saved, err)
+			}
+		}
+
+		// values from env and defaults are not saved back
+		file := testutil.TempConfig(t, "yml", "appname: app\ndb:\n  name: db\n  password: file_password\n")
+		testutil.WithEnv(t, map[string]string{"DBPassword": "env_password", "CONFIGOR_DB_NAME": "env_db"}, func() {
+			var result Config
+			loadResult, err := configor.LoadWithResult(&result, file)
+			if err != nil || result.DB.Password != "env_password" || result.DB.User != "root" {
+				t.Fatalf("env and defaults should be loaded, but got %#v, %v", result.DB, err)
+			}
+
+			result.APPName = "edited"
+			if err := loadResult.SaveBack(); err != nil {
+				t.Errorf("No error should happen when save back, but got %v", err)
+			}
+
+			content, _ := ioutil.ReadFile(file)
+			var saved Config
+			if err := yaml.Unmarshal(content, &saved); err != nil || saved.APPName != "edited" || saved.DB.Password != "file_password" || saved.DB.Name != "db" || saved.DB.User != "" {
+				t.Errorf("only values from the file should be saved back, but got %#v, %v", saved, err)
+			}
+			if strings.Contains(string(content), "env_password") {
+				t.Errorf("env password shouldn't be saved, but got %v", string(content))
+			}
+		})
+	} else {
+		t.Errorf("failed to marshal config as toml, got %v", err)
+	}
+}
+
+func TestSaveYAMLWithStyle(t *testing.T) {
+	type StyleConfig struct {
+		AllowedIPs []string `yaml_style:"flow"`
+		DB         struct {
+			Hosts []string
+		}
+	}
+
+	config := StyleConfig{AllowedIPs: []string{"10.0.0.1", "10.0.0.2"}}
+	config.DB.Hosts = []string{"db1", "db2"}
+
+	bytes, err := configor.SaveBytes(config, "yaml")
+	if err != nil {
+		t.Errorf("No error should happen when save yaml, but got %v", err)
+	}
+
+	if expected := "allowedips: [10.0.0.1, 10.0.0.2]\ndb:\n  hosts:\n    - db1\n    - db2\n"; string(bytes) != expected {
+		t.Errorf("field tagged with yaml_style should be saved in flow style, expect %q, but got %q", expected, bytes)
+	}
+
+	bytes, err = configor.New(configor.WithYAMLStyle(yamlv3.FlowStyle)).SaveBytes(config, "yml")
+	if err != nil {
+		t.Errorf("No error should happen when save yaml, but got %v", err)
+	}
+
+	var result StyleConfig
+	if !strings.HasPrefix(string(bytes), "{") || yaml.Unmarshal(bytes, &result) != nil || !reflect.DeepEqual(result, config) {
+		t.Errorf("yaml should be saved in flow style, but got %q", bytes)
+	}
+}
+
+func TestSaveWithFieldOrder(t *testing.T) {
+	type Credentials struct {
+		Password string `order:"2"`
+		User     string `order:"1"`
+	}
+
+	type OrderConfig struct {
+		Credentials Credentials `order:"3"`
+		Port        int         `order:"2"`
+		Name        string
+		Host        string `order:"1"`
+	}
+
+	config := OrderConfig{Credentials: Credentials{Password: "pass", User: "root"}, Port: 3306, Name: "db", Host: "localhost"}
+	for format, expected := range map[string]string{
+		"yaml": "host: localhost\nport: 3306\ncredentials:\n  user: root\n  password: pass\nname: db\n",
+		"toml": "Host = \"localhost\"\nPort = 3306\nName = \"db\"\n\n[Credentials]\n  User = \"root\"\n  Password = \"pass\"\n",
+		"json": `{"Host":"localhost","Port":3306,"Credentials":{"User":"root","Password":"pass"},"Name":"db"}`,
+	} {
+		if bytes, err := configor.SaveBytes(config, format); err != nil || string(bytes) != expected {
+			t.Errorf("%v fields should be saved in order, expect %q, but got %q, %v", format, expected, bytes, err)
+		}
+	}
+}
+
+type orderedMarshaler struct {
+	Second string `order:"2"`
+	First  string `order:"1"`
+}
+
+func (value orderedMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal(value.First + "/" + value.Second)
+}
+
+func (value orderedMarshaler) MarshalYAML() (interface{}, error) {
+	return value.First + "/" + value.Second, nil
+}
+
+func TestSaveWithFieldOrderInSlicesAndMaps(t *testing.T) {
+	type Server struct {
+		Port int    `order:"2"`
+		Host string `order:"1"`
+	}
+
+	type OrderConfig struct {
+		Servers  []Server
+		Replicas map[string]*Server
+		Custom   orderedMarshaler
+	}
+
+	config := OrderConfig{
+		Servers:  []Server{{Port: 80, Host: "a"}, {Port: 443, Host: "b"}},
+		Replicas: map[string]*Server{"east": {Port: 5432, Host: "c"}},
+		Custom:   orderedMarshaler{Second: "2", First: "1"},
+	}
+	for format, expected := range map[string]string{
+		"yaml": "servers:\n- host: a\n  port: 80\n- host: b\n  port: 443\nreplicas:\n  east:\n    host: c\n    port: 5432\ncustom: 1/2\n",
+		"json": `{"Servers":[{"Host":"a","Port":80},{"Host":"b","Port":443}],"Replicas":{"east":{"Host":"c","Port":5432}},"Custom":"1/2"}`,
+	} {
+		if bytes, err := configor.SaveBytes(config, format); err != nil || string(bytes) != expected {
+			t.Errorf("%v fields of structs in slices and maps should be saved in order, expect %q, but got %q, %v", format, expected, bytes, err)
+		}
+	}
+}
+
+func TestSaveJSONWithIndentAndSortedKeys(t *testing.T) {
+	config := struct {
+		Name   string
+		Port   int
+		Labels map[string]string
+	}{Name: "app", Port: 8080, Labels: map[string]string{"b": "2", "a": "1"}}
+
+	bytes, err := configor.New(configor.WithJSONIndent("", "  ")).SaveBytes(config, "json")
+	if expected := "{\n  \"Name\": \"app\",\n  \"Port\": 8080,\n  \"Labels\": {\n    \"a\": \"1\",\n    \"b\": \"2\"\n  }\n}"; err != nil || string(bytes) != expected {
+		t.Errorf("json should be indented, expect %q, but got %q, %v", expected, bytes, err)
+	}
+
+	bytes, err = configor.New(configor.WithJSONSortKeys(true)).SaveBytes(config, "json")
+	if expected := `{"Labels":{"a":"1","b":"2"},"Name":"app","Port":8080}`; err != nil || string(bytes) != expected {
+		t.Errorf("json keys should be sorted, expect %q, but got %q, %v", expected, bytes, err)
+	}
+}
+
 func TestDefaultValue(t *testing.T) {
 	config := generateDefaultConfig()
 	config.APPName = ""
@@ -186,6 +375,39 @@ func TestLoadConfigurationByEnvironment(t *testing.T) {
 	}
 }
 
+func TestEnvInheritance(t *testing.T) {
+	type InheritConfig struct {
+		APPName string
+		Host    string
+		Port    int
+	}
+
+	dir, _ := ioutil.TempDir("", "configor")
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "config.yml")
+	ioutil.WriteFile(file, []byte("appname: base\nhost: localhost\nport: 80\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "config.production.yml"), []byte("appname: production\nhost: example.com\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "config.staging.yml"), []byte("appname: staging\n"), 0644)
+
+	os.Setenv("CONFIGOR_ENV", "staging")
+	defer os.Setenv("CONFIGOR_ENV", "")
+
+	var result InheritConfig
+	loader := configor.New(configor.WithEnvInheritance(map[string]string{"staging": "production", "development": "staging"}))
+	if err := loader.Load(&result, file); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+	if !reflect.DeepEqual(result, InheritConfig{APPName: "staging", Host: "example.com", Port: 80}) {
+		t.Errorf("env file of parent environment should be loaded before current environment, but got %#v", result)
+	}
+
+	var cyclic InheritConfig
+	loader = configor.New(configor.WithEnvInheritance(map[string]string{"staging": "production", "production": "staging"}))
+	if err := loader.Load(&cyclic, file); err == nil || !strings.Contains(err.Error(), "staging -> production -> staging") {
+		t.Errorf("should got error for circular inheritance, but got %v", err)
+	}
+}
+
 func TestOverwriteConfigurationWithEnvironmentWithDefaultPrefix(t *testing.T) {
 	config := generateDefaultConfig()
 
@@ -288,13 +510,3190 @@ func TestReadFromEnvironmentWithSpecifiedEnvName(t *testing.T) {
 }
 
 func TestENV(t *testing.T) {
-	if configor.ENV() != "test" {
+	if configor.ENV() != "test" || !configor.ENV().IsTest() || configor.ENV().IsDevelopment() {
 		t.Errorf("Env should be test when running `go test`")
 	}
 
 	os.Setenv("CONFIGOR_ENV", "production")
 	defer os.Setenv("CONFIGOR_ENV", "")
-	if configor.ENV() != "production" {
+	if configor.ENV() != configor.Production || !configor.ENV().IsProduction() || configor.ENV().IsTest() {
 		t.Errorf("Env should be production when set it with CONFIGOR_ENV")
 	}
 }
+
+func TestDefaultSentinelInEnvironment(t *testing.T) {
+	config := generateDefaultConfig()
+	config.DB.Port = 0
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+			var result Config
+			os.Setenv("CONFIGOR_DB_PORT", configor.Default)
+			defer os.Setenv("CONFIGOR_DB_PORT", "")
+			if err := configor.Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if result.DB.Port != 3306 {
+				t.Errorf("DB.Port should be set to default value when env is the default sentinel, but got %v", result.DB.Port)
+			}
+		}
+	} else {
+		t.Errorf("failed to marshal config")
+	}
+}
+
+func TestSaveBytes(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := configor.SaveBytes(config, "yml"); err == nil {
+		var result Config
+		if err := yaml.Unmarshal(bytes, &result); err != nil || !reflect.DeepEqual(result, config) {
+			t.Errorf("SaveBytes should marshal configuration as yaml, but got %v", err)
+		}
+	} else {
+		t.Errorf("No error should happen when marshal configuration, but got %v", err)
+	}
+
+	if bytes, err := configor.SaveBytes(config, ".json"); err == nil {
+		var result Config
+		if err := json.Unmarshal(bytes, &result); err != nil || !reflect.DeepEqual(result, config) {
+			t.Errorf("SaveBytes should marshal configuration as json, but got %v", err)
+		}
+	} else {
+		t.Errorf("No error should happen when marshal configuration, but got %v", err)
+	}
+
+	if _, err := configor.SaveBytes(config, "ini"); err == nil {
+		t.Errorf("Should got error when marshal configuration with unknown format")
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	config := generateDefaultConfig()
+
+	for _, format := range []string{"yml", "toml", ".json"} {
+		reader, err := configor.NewReader(config, format)
+		if err != nil {
+			t.Errorf("No error should happen when create reader for %v, but got %v", format, err)
+			continue
+		}
+
+		data, err := ioutil.ReadAll(reader)
+		if bytes, _ := configor.SaveBytes(config, format); err != nil || string(data) != string(bytes) {
+			t.Errorf("NewReader should stream the same content as SaveBytes for %v, but got %v, %v", format, string(data), err)
+		}
+		reader.Close()
+	}
+
+	// closing before the end stops serializing
+	reader, err := configor.NewReader(config, "json")
+	if err != nil {
+		t.Fatalf("No error should happen when create reader, but got %v", err)
+	}
+	buf := make([]byte, 1)
+	if _, err := reader.Read(buf); err != nil {
+		t.Errorf("No error should happen when read, but got %v", err)
+	}
+	if err := reader.Close(); err != nil {
+		t.Errorf("No error should happen when close reader, but got %v", err)
+	}
+	if _, err := reader.Read(buf); err == nil {
+		t.Errorf("Should got error when read closed reader")
+	}
+
+	if _, err := configor.NewReader(config, "ini"); err == nil {
+		t.Errorf("Should got error when create reader with unknown format")
+	}
+}
+
+func TestRegisterExtension(t *testing.T) {
+	configor.RegisterExtension(".conf", "yaml")
+	config := generateDefaultConfig()
+
+	if file, err := ioutil.TempFile("/tmp", "configor*.conf"); err == nil {
+		file.Close()
+		defer os.Remove(file.Name())
+
+		if err := configor.Save(config, file.Name()); err != nil {
+			t.Errorf("No error should happen when save configuration with registered extension, but got %v", err)
+		}
+
+		data, _ := ioutil.ReadFile(file.Name())
+		var yamlResult Config
+		if err := yaml.Unmarshal(data, &yamlResult); err != nil || !reflect.DeepEqual(yamlResult, config) {
+			t.Errorf("Configuration should be saved as yaml, but got %v", string(data))
+		}
+
+		var result Config
+		loadResult, err := configor.LoadWithResult(&result, file.Name())
+		if err != nil || result.APPName != config.APPName || !reflect.DeepEqual(result.Contacts, config.Contacts) {
+			t.Errorf("Configuration should be loaded as yaml, but got %#v, %v", result, err)
+		} else if loadResult.Format != "yaml" {
+			t.Errorf("Format should be the registered format, but got %v", loadResult.Format)
+		}
+	}
+}
+
+func TestFieldTransformer(t *testing.T) {
+	type TransformConfig struct {
+		Name string
+		Dir  string `default:"~/data"`
+		DB   struct {
+			Host string
+			Port int
+		}
+	}
+
+	os.Setenv("CONFIGOR_DB_HOST", "  localhost ")
+	defer os.Setenv("CONFIGOR_DB_HOST", "")
+
+	var paths []string
+	var result TransformConfig
+	err := configor.New(configor.WithFieldTransformer(func(path, value string) string {
+		paths = append(paths, path)
+		return strings.Replace(strings.TrimSpace(value), "~", "/home/configor", 1)
+	})).Load(&result)
+	if err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Dir != "/home/configor/data" || result.DB.Host != "localhost" {
+		t.Errorf("string fields should be transformed, but got %#v", result)
+	}
+	if !reflect.DeepEqual(paths, []string{"Name", "Dir", "DB.Host"}) {
+		t.Errorf("transformer should be called with paths of string fields, but got %v", paths)
+	}
+}
+
+func TestLoadTimeLocation(t *testing.T) {
+	type LocationConfig struct {
+		Name     string
+		Location *time.Location `default:"UTC"`
+		Server   struct {
+			Location *time.Location
+		}
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		file.Write([]byte(`{"Name": "location"}`))
+
+		var result LocationConfig
+		os.Setenv("CONFIGOR_SERVER_LOCATION", "Asia/Shanghai")
+		defer os.Setenv("CONFIGOR_SERVER_LOCATION", "")
+		if err := configor.Load(&result, file.Name()); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		if result.Location != time.UTC || result.Server.Location == nil || result.Server.Location.String() != "Asia/Shanghai" {
+			t.Errorf("time location should be loaded from default tag and env, but got %v, %v", result.Location, result.Server.Location)
+		}
+
+		os.Setenv("CONFIGOR_SERVER_LOCATION", "Invalid/Timezone")
+		err := configor.Load(&LocationConfig{}, file.Name())
+		if configErr, ok := err.(*configor.ConfigError); !ok || configErr.Field != "Server.Location" || configErr.Value != "Invalid/Timezone" {
+			t.Errorf("Should got ConfigError when load invalid timezone, but got %v", err)
+		}
+	}
+}
+
+func TestLoadBigNumbers(t *testing.T) {
+	type BigConfig struct {
+		Modulus *big.Int `default:"340282366920938463463374607431768211457"`
+		Wei     *big.Int
+		Ratio   *big.Float `default:"3.14159265358979323846264338327950288"`
+	}
+
+	os.Setenv("CONFIGOR_WEI", "1000000000000000000000")
+	defer os.Setenv("CONFIGOR_WEI", "")
+
+	var result BigConfig
+	if err := configor.Load(&result); err != nil {
+		t.Errorf("No error should happen when load big numbers, but got %v", err)
+	}
+
+	if result.Modulus == nil || result.Modulus.String() != "340282366920938463463374607431768211457" {
+		t.Errorf("big.Int should be loaded from default tag, but got %v", result.Modulus)
+	}
+	if result.Wei == nil || result.Wei.String() != "1000000000000000000000" {
+		t.Errorf("big.Int should be loaded from env, but got %v", result.Wei)
+	}
+	if result.Ratio == nil || result.Ratio.Text('f', 5) != "3.14159" {
+		t.Errorf("big.Float should be loaded from default tag, but got %v", result.Ratio)
+	}
+
+	os.Setenv("CONFIGOR_WEI", "1e21")
+	var configError *configor.ConfigError
+	if err := configor.Load(&BigConfig{}); !errors.As(err, &configError) || configError.Field != "Wei" || configError.Value != "1e21" {
+		t.Errorf("Should got ConfigError when load invalid big integer, but got %v", err)
+	}
+}
+
+func TestRangeCheck(t *testing.T) {
+	type RangeConfig struct {
+		Port    uint16
+		Timeout int32
+		Limit   int64
+		Servers []struct {
+			Weight uint8
+		}
+	}
+
+	loader := configor.New(configor.WithRangeCheck(true))
+	for content, field := range map[string]string{
+		"port: 70000\n":                                "Port",
+		"timeout = 3000000000\n":                       "Timeout",
+		`{"Servers": [{"Weight": 1}, {"Weight": -1}]}`: "Servers[1].Weight",
+	} {
+		format := "yml"
+		if strings.HasPrefix(content, "{") {
+			format = "json"
+		} else if strings.Contains(content, "=") {
+			format = "toml"
+		}
+
+		file := testutil.TempConfig(t, format, content)
+		var configError *configor.ConfigError
+		if err := loader.Load(&RangeConfig{}, file); !errors.As(err, &configError) || configError.Field != field {
+			t.Errorf("Should got ConfigError of %v when number overflows, but got %v", field, err)
+		}
+	}
+
+	var result RangeConfig
+	file := testutil.TempConfig(t, "json", `{"Port": 65535, "Timeout": -2147483648, "Limit": 9223372036854775807}`)
+	if err := loader.Load(&result, file); err != nil || result.Port != 65535 || result.Limit != 9223372036854775807 {
+		t.Errorf("numbers in range should be loaded, but got %#v, %v", result, err)
+	}
+}
+
+func TestTypeCoercion(t *testing.T) {
+	type CoercionConfig struct {
+		Debug   bool
+		Verbose bool `default:"F"`
+		Ratio   float32
+		Timeout time.Duration `default:"1s"`
+	}
+
+	os.Setenv("CONFIGOR_DEBUG", "1")
+	os.Setenv("CONFIGOR_RATIO", "3.14")
+	defer os.Setenv("CONFIGOR_DEBUG", "")
+	defer os.Setenv("CONFIGOR_RATIO", "")
+
+	if err := configor.Load(&CoercionConfig{}); err == nil {
+		t.Errorf("Should got error when load 1 into bool field without type coercion")
+	}
+
+	var result CoercionConfig
+	if err := configor.New(configor.WithTypeCoercion(true)).Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations with type coercion, but got %v", err)
+	}
+	if !reflect.DeepEqual(result, CoercionConfig{Debug: true, Ratio: 3.14, Timeout: time.Second}) {
+		t.Errorf("values should be coerced into fields, but got %#v", result)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	type FlattenConfig struct {
+		APPName string
+		Token   string `secret:"true"`
+		Hosts   []string
+		Labels  map[string]string
+		Timeout time.Duration
+		DB      struct {
+			Port int
+		}
+		Contacts []struct {
+			Email string
+		}
+	}
+
+	config := FlattenConfig{APPName: "configor", Token: "tok-12345", Hosts: []string{"a", "b"}, Labels: map[string]string{"env": "prod"}, Timeout: time.Second}
+	config.DB.Port = 5432
+	config.Contacts = append(config.Contacts, struct{ Email string }{Email: "wosmvp@gmail.com"})
+
+	expected := map[string]string{
+		"APPName": "configor", "Token": "tok-12345", "Hosts[0]": "a", "Hosts[1]": "b", "Labels[env]": "prod",
+		"Timeout": "1s", "DB.Port": "5432", "Contacts[0].Email": "wosmvp@gmail.com",
+	}
+	flattened := configor.Flatten(&config)
+	if !reflect.DeepEqual(flattened, expected) {
+		t.Errorf("config should be flattened, but got %v", flattened)
+	}
+
+	var overrides []string
+	for path, value := range flattened {
+		overrides = append(overrides, path+"="+value)
+	}
+	var result FlattenConfig
+	if err := configor.ApplyOverrides(&result, overrides); err != nil || !reflect.DeepEqual(result, config) {
+		t.Errorf("flattened config should be applied back with ApplyOverrides, but got %#v, %v", result, err)
+	}
+
+	if redacted := configor.New(configor.WithSecretRedaction(true)).Flatten(&config); redacted["Token"] != "******" || redacted["APPName"] != "configor" {
+		t.Errorf("secret fields should be redacted, but got %v", redacted)
+	}
+}
+
+func TestExcludeFields(t *testing.T) {
+	type ExcludeConfig struct {
+		Name    string
+		Ignored string `configor:"-" default:"ignored" required:"true"`
+		Runtime struct {
+			Host string `required:"true"`
+			Port int    `default:"8080"`
+		} `configor:"-"`
+	}
+
+	os.Setenv("CONFIGOR_IGNORED", "from_env")
+	defer os.Setenv("CONFIGOR_IGNORED", "")
+
+	var result ExcludeConfig
+	if err := configor.Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations with excluded fields, but got %v", err)
+	}
+	if result.Ignored != "" || result.Runtime.Port != 0 {
+		t.Errorf("excluded fields should not be processed, but got %#v", result)
+	}
+	if _, ok := configor.DumpEnv(&result)["CONFIGOR_IGNORED"]; ok {
+		t.Errorf("excluded fields should not be dumped")
+	}
+}
+
+func TestRefreshEnv(t *testing.T) {
+	type RefreshConfig struct {
+		Name  string `default:"configor"`
+		Port  int
+		Debug bool
+	}
+
+	file := testutil.TempConfig(t, "yml", "port: 80\n")
+	loader := configor.New()
+	var result RefreshConfig
+	if err := loader.Load(&result, file); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	os.Setenv("CONFIGOR_PORT", "8080")
+	os.Setenv("CONFIGOR_DEBUG", "true")
+	defer os.Setenv("CONFIGOR_PORT", "")
+	defer os.Setenv("CONFIGOR_DEBUG", "")
+	// files changed are not re-read
+	ioutil.WriteFile(file, []byte("port: 90\nname: changed\n"), 0644)
+
+	if err := loader.RefreshEnv(&result); err != nil {
+		t.Errorf("No error should happen when refresh env, but got %v", err)
+	}
+	if !reflect.DeepEqual(result, RefreshConfig{Name: "configor", Port: 8080, Debug: true}) {
+		t.Errorf("only env should be re-applied, but got %#v", result)
+	}
+
+	os.Setenv("CONFIGOR_PORT", "invalid")
+	os.Setenv("CONFIGOR_DEBUG", "false")
+	if err := loader.RefreshEnv(&result); err == nil {
+		t.Errorf("Should got error when refresh invalid env")
+	}
+	if result.Port != 8080 || !result.Debug {
+		t.Errorf("config should be unchanged if failed to refresh env, but got %#v", result)
+	}
+
+	if err := loader.RefreshEnv(result); err == nil {
+		t.Errorf("Should got error when refresh config that isn't a pointer")
+	}
+}
+
+func TestFileReferences(t *testing.T) {
+	type FileRefConfig struct {
+		Password string `file:"db_password"`
+		Token    string
+		Name     string `default:"app"`
+	}
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "db_password"), []byte("secret\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "token"), []byte("token\n"), 0600)
+
+	t.Run("Read", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_TOKEN_FILE": filepath.Join(dir, "token")}, func() {
+			var result FileRefConfig
+			loader := configor.New(configor.WithBaseDir(dir), configor.WithFileEnvSuffix("_FILE"), configor.WithFileCheck(true))
+			if err := loader.Load(&result); err != nil {
+				t.Errorf("No error should happen when load referenced files, but got %v", err)
+			}
+			if result.Password != "secret" || result.Token != "token" || result.Name != "app" {
+				t.Errorf("fields should be read from referenced files, but got %#v", result)
+			}
+
+			// file envs are disabled by default
+			var disabled FileRefConfig
+			if err := configor.New(configor.WithBaseDir(dir)).Load(&disabled); err != nil || disabled.Token != "" {
+				t.Errorf("file envs shouldn't be read without the suffix, but got %#v, %v", disabled, err)
+			}
+		})
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_TOKEN_FILE": filepath.Join(dir, "missing_token")}, func() {
+			missingDir := t.TempDir()
+			loader := configor.New(configor.WithBaseDir(missingDir), configor.WithFileEnvSuffix("_FILE"))
+			err := loader.CheckFiles(&FileRefConfig{})
+			if err == nil || !strings.Contains(err.Error(), "Password: ") || !strings.Contains(err.Error(), "Token: ") {
+				t.Errorf("Should got error listing all missing files, but got %v", err)
+			}
+
+			loader = configor.New(configor.WithBaseDir(missingDir), configor.WithFileEnvSuffix("_FILE"), configor.WithFileCheck(true))
+			if err := loader.Load(&FileRefConfig{}); err == nil || !strings.Contains(err.Error(), "missing referenced files") {
+				t.Errorf("Should got error of missing files when load with file check, but got %v", err)
+			}
+		})
+	})
+}
+
+func TestSliceEnvWithIndices(t *testing.T) {
+	type Contact struct {
+		Name  string
+		Email string `default:"nobody@example.com"`
+	}
+	type SliceConfig struct {
+		Hosts    []string
+		Ports    []int
+		Contacts []Contact
+		Admins   []*Contact
+	}
+
+	file := testutil.TempConfig(t, "yml", "hosts: [a, b, c]\nports: [80]\ncontacts:\n  - name: jinzhu\n")
+
+	t.Run("OverrideAndGrow", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{
+			// override in place
+			"CONFIGOR_HOSTS_1": "B",
+			// append with a gap at index 1 and 2
+			"CONFIGOR_PORTS_3": "443",
+			// grow slices of structs
+			"CONFIGOR_CONTACTS_1_NAME": "admin",
+			"CONFIGOR_ADMINS_0_NAME":   "root",
+		}, func() {
+			var result SliceConfig
+			if err := configor.Load(&result, file); err != nil {
+				t.Errorf("No error should happen when load slices from env, but got %v", err)
+			}
+
+			expected := SliceConfig{
+				Hosts:    []string{"a", "B", "c"},
+				Ports:    []int{80, 0, 0, 443},
+				Contacts: []Contact{{Name: "jinzhu", Email: "nobody@example.com"}, {Name: "admin", Email: "nobody@example.com"}},
+				Admins:   []*Contact{{Name: "root", Email: "nobody@example.com"}},
+			}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("slices should be overridden and grown by env with indices, expect %#v, but got %#v", expected, result)
+			}
+		})
+	})
+
+	t.Run("WholeSliceEnv", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_HOSTS": "[x]", "CONFIGOR_HOSTS_2": "z"}, func() {
+			var result SliceConfig
+			if err := configor.Load(&result, file); err != nil || !reflect.DeepEqual(result.Hosts, []string{"x", "", "z"}) {
+				t.Errorf("env with indices should be applied on the whole-slice env, but got %#v, %v", result.Hosts, err)
+			}
+		})
+	})
+
+	t.Run("InvalidElement", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_PORTS_0": "http"}, func() {
+			if err := configor.Load(&SliceConfig{}, file); err == nil || !strings.Contains(err.Error(), "Ports[0]") {
+				t.Errorf("Should got error when load invalid element from env, but got %v", err)
+			}
+		})
+	})
+
+	t.Run("HugeIndex", func(t *testing.T) {
+		for _, index := range []string{"1025", "99999999999", "99999999999999999999"} {
+			testutil.WithEnv(t, map[string]string{"CONFIGOR_HOSTS_" + index: "x"}, func() {
+				var result SliceConfig
+				loadResult, err := configor.LoadWithResult(&result, file)
+				if err != nil || len(result.Hosts) > 3 {
+					t.Errorf("env exceeding the max slice index should be ignored, but got %v, %v", len(result.Hosts), err)
+				}
+				if !strings.Contains(loadResult.Warnings.String(), "max slice index") {
+					t.Errorf("env exceeding the max slice index should be warned, but got %v", loadResult.Warnings)
+				}
+			})
+		}
+
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_HOSTS_3": "x"}, func() {
+			var result SliceConfig
+			if loadResult, err := configor.New(configor.WithMaxSliceIndex(2)).LoadWithResult(&result, file); err != nil || len(result.Hosts) > 3 || !strings.Contains(loadResult.Warnings.String(), "CONFIGOR_HOSTS_3") {
+				t.Errorf("env exceeding the configured max slice index should be ignored with a warning, but got %v, %v, %v", result.Hosts, err, loadResult.Warnings)
+			}
+		})
+	})
+}
+
+func TestRequiredWithCustomMessage(t *testing.T) {
+	type RequiredConfig struct {
+		Host string `required:"Please set CONFIGOR_HOST to the database hostname"`
+		Port int    `required:"false"`
+	}
+
+	if err := configor.Load(&RequiredConfig{}); err == nil || err.Error() != "Please set CONFIGOR_HOST to the database hostname" {
+		t.Errorf("Should got the custom message of required tag, but got %v", err)
+	}
+
+	var phaseError *configor.PhaseError
+	if _, err := configor.New(configor.WithErrorCollection(true)).LoadWithResult(&RequiredConfig{}); !errors.As(err, &phaseError) || phaseError.Field != "Host" {
+		t.Errorf("Should got required error of Host, but got %v", err)
+	}
+
+	if infos := configor.Describe(&RequiredConfig{}); !infos[0].Required || infos[1].Required {
+		t.Errorf("fields with custom message should be described as required, but got %#v", infos)
+	}
+}
+
+func TestEnvFormatJSON(t *testing.T) {
+	type Server struct {
+		Host string
+		Port int
+	}
+	type EnvFormatConfig struct {
+		Servers map[string]Server `env_format:"json"`
+		Ports   []int             `env_format:"json"`
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		testutil.WithEnv(t, map[string]string{
+			"CONFIGOR_SERVERS": `{"1": {"Host": "db:5432", "Port": 5432}, "a #b": {"Host": "[::1]"}}`,
+			"CONFIGOR_PORTS":   `[80, 443]`,
+		}, func() {
+			var result EnvFormatConfig
+			if err := configor.Load(&result); err != nil {
+				t.Errorf("No error should happen when load json env, but got %v", err)
+			}
+
+			expected := EnvFormatConfig{Servers: map[string]Server{"1": {Host: "db:5432", Port: 5432}, "a #b": {Host: "[::1]"}}, Ports: []int{80, 443}}
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("env should be decoded as json, expect %#v, but got %#v", expected, result)
+			}
+		})
+	})
+
+	t.Run("NotJSON", func(t *testing.T) {
+		// valid yaml, but not json
+		testutil.WithEnv(t, map[string]string{"CONFIGOR_SERVERS": `{primary: {host: db}}`}, func() {
+			if err := configor.Load(&EnvFormatConfig{}); err == nil || !strings.Contains(err.Error(), "Servers") {
+				t.Errorf("Should got error when env isn't json, but got %v", err)
+			}
+		})
+	})
+}
+
+func TestOnceTag(t *testing.T) {
+	type onceConfig struct {
+		Host string `once:"true"`
+		Port int    `once:"true"`
+		Name string
+	}
+
+	base := testutil.TempConfig(t, "yml", "host: db.internal\nname: base\n")
+	override := testutil.TempConfig(t, "yml", "host: db.override\nport: 5432\nname: override\n")
+	testutil.WithEnv(t, map[string]string{"CONFIGOR_HOST": "db.env", "CONFIGOR_PORT": "3306"}, func() {
+		var result onceConfig
+		if err := configor.Load(&result, override, base); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		// host is set by the base file first, port is blank until the override file
+		if result.Host != "db.internal" || result.Port != 5432 || result.Name != "override" {
+			t.Errorf("fields tagged with once shouldn't be overridden after set, but got %#v", result)
+		}
+	})
+
+	testutil.WithEnv(t, map[string]string{"CONFIGOR_PORT": "3306"}, func() {
+		var result onceConfig
+		if err := configor.Load(&result, base); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+		if result.Port != 3306 {
+			t.Errorf("blank fields tagged with once should be set from env, but got %#v", result)
+		}
+	})
+
+	t.Run("PointerAndMap", func(t *testing.T) {
+		type onceServer struct {
+			Host string `once:"true"`
+		}
+		type oncePointerConfig struct {
+			Name    *string `once:"true"`
+			Servers map[string]onceServer
+		}
+
+		base := testutil.TempConfig(t, "yml", "name: base\nservers:\n  db:\n    host: db.internal\n")
+		override := testutil.TempConfig(t, "yml", "name: override\nservers:\n  db:\n    host: db.override\n")
+
+		var result oncePointerConfig
+		if err := configor.Load(&result, override, base); err != nil {
+			t.Fatalf("No error should happen when load configurations, but got %v", err)
+		}
+		if result.Name == nil || *result.Name != "base" {
+			t.Errorf("pointer fields tagged with once shouldn't be overridden after set, but got %v", result.Name)
+		}
+		if result.Servers["db"].Host != "db.internal" {
+			t.Errorf("fields tagged with once in maps shouldn't be overridden after set, but got %#v", result.Servers)
+		}
+	})
+}
+
+type FinalizedDB struct {
+	Host string `required:"true"`
+	Port int    `default:"5432"`
+	Addr string
+}
+
+func (db *FinalizedDB) Finalize() error {
+	db.Addr = fmt.Sprintf("%v:%v", db.Host, db.Port)
+	return nil
+}
+
+type FinalizedConfig struct {
+	User     string `default:"root"`
+	DB       FinalizedDB
+	Replicas map[string]FinalizedDB
+	DSN      string
+}
+
+func (config *FinalizedConfig) Finalize() error {
+	if config.User == "nobody" {
+		return errors.New("user nobody is not allowed")
+	}
+	// nested structs are finalized first
+	config.DSN = fmt.Sprintf("postgres://%v@%v", config.User, config.DB.Addr)
+	return nil
+}
+
+func TestFinalizer(t *testing.T) {
+	var result FinalizedConfig
+	if err := configor.Load(&result, testutil.TempConfig(t, "yml", "db:\n  host: localhost\n")); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB.Addr != "localhost:5432" || result.DSN != "postgres://root@localhost:5432" {
+		t.Errorf("computed fields should be derived after loading, but got %#v", result)
+	}
+
+	var replicas FinalizedConfig
+	if err := configor.Load(&replicas, testutil.TempConfig(t, "yml", "db:\n  host: localhost\nreplicas:\n  east:\n    host: east.db\n")); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+	if replicas.Replicas["east"].Addr != "east.db:5432" {
+		t.Errorf("structs in maps should be finalized, but got %#v", replicas.Replicas)
+	}
+
+	var invalid FinalizedConfig
+	if err := configor.Load(&invalid, testutil.TempConfig(t, "yml", "user: admin\n")); err == nil || invalid.DSN != "" {
+		t.Errorf("configurations failed to load shouldn't be finalized, but got %#v, %v", invalid, err)
+	}
+
+	if err := configor.Load(&FinalizedConfig{}, testutil.TempConfig(t, "yml", "user: nobody\ndb:\n  host: localhost\n")); err == nil || err.Error() != "user nobody is not allowed" {
+		t.Errorf("Should got error of Finalize, but got %v", err)
+	}
+}
+
+func TestEnvPresenceFlag(t *testing.T) {
+	type FlagConfig struct {
+		Debug   bool `env_presence:"true"`
+		Verbose bool `env_presence:"true"`
+		Color   bool
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		file.Write([]byte(`{}`))
+
+		var result FlagConfig
+		os.Setenv("CONFIGOR_DEBUG", "")
+		os.Setenv("CONFIGOR_COLOR", "")
+		os.Unsetenv("CONFIGOR_VERBOSE")
+		defer os.Unsetenv("CONFIGOR_DEBUG")
+		defer os.Unsetenv("CONFIGOR_COLOR")
+		if err := configor.Load(&result, file.Name()); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		if !result.Debug || result.Verbose || result.Color {
+			t.Errorf("only presence flags with env set should be true, but got %#v", result)
+		}
+	}
+}
+
+func TestOverwriteConfigurationWithMultiplePrefixes(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+			var result Config
+			os.Setenv("NEWAPP_APPNAME", "config2")
+			os.Setenv("OLDAPP_APPNAME", "config3")
+			os.Setenv("OLDAPP_DB_NAME", "db_name")
+			defer os.Setenv("NEWAPP_APPNAME", "")
+			defer os.Setenv("OLDAPP_APPNAME", "")
+			defer os.Setenv("OLDAPP_DB_NAME", "")
+			if err := configor.New(configor.WithPrefixes("NEWAPP", "OLDAPP")).Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if result.APPName != "config2" || result.DB.Name != "db_name" {
+				t.Errorf("env should be read from the first prefix that's set, but got %v, %v", result.APPName, result.DB.Name)
+			}
+		}
+	}
+}
+
+func TestDescribeMapsConcurrently(t *testing.T) {
+	type Server struct {
+		Host string `default:"localhost"`
+	}
+	type MapConfig struct {
+		Servers map[string]Server
+	}
+
+	config := MapConfig{Servers: map[string]Server{"primary": {Host: "db1"}, "replica": {Host: "db2"}}}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// maps are only read when describing, fails with concurrent map writes (or -race) otherwise
+			for j := 0; j < 100; j++ {
+				configor.Describe(&config)
+				configor.Flatten(&config)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(config.Servers) != 2 || config.Servers["primary"].Host != "db1" {
+		t.Errorf("maps shouldn't be changed when describing, but got %#v", config.Servers)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	type DescribeConfig struct {
+		APPName string `default:"configor" desc:"name of the application"`
+		DB      struct {
+			Password string `required:"true" env:"DBPassword"`
+			Port     uint   `default:"3306"`
+		}
+	}
+
+	fields := configor.Describe(&DescribeConfig{})
+	expected := []configor.FieldInfo{
+		{Path: "APPName", Type: "string", Default: "configor", Env: "CONFIGOR_APPNAME", Desc: "name of the application"},
+		{Path: "DB.Password", Type: "string", Required: true, Env: "DBPassword"},
+		{Path: "DB.Port", Type: "uint", Default: "3306", Env: "CONFIGOR_DB_PORT"},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Describe should return metadata of all fields, but got %#v", fields)
+	}
+}
+
+func TestSecretMaskedInErrors(t *testing.T) {
+	type SecretConfig struct {
+		Token    int    `secret:"true"`
+		Password string `secret:"true" default:"s3cr3t"`
+		Mode     string `required_if:"Password=s3cr3t"`
+	}
+
+	os.Setenv("CONFIGOR_TOKEN", "tok-12345")
+	defer os.Unsetenv("CONFIGOR_TOKEN")
+
+	var result SecretConfig
+	err := configor.New(configor.WithErrorCollection(true)).Load(&result)
+	if err == nil {
+		t.Fatalf("error should happen for invalid token")
+	}
+
+	if message := err.Error(); strings.Contains(message, "tok-12345") || strings.Contains(message, "s3cr3t") || !strings.Contains(message, "****") {
+		t.Errorf("secret values should be masked in errors, but got %v", message)
+	}
+
+	var configError *configor.ConfigError
+	if !errors.As(err, &configError) || configError.Field != "Token" {
+		t.Errorf("masked error should wrap the original error, but got %#v", configError)
+	}
+}
+
+type Node struct {
+	Name string
+	Next *Node
+}
+
+func TestLoadWithMaxDepth(t *testing.T) {
+	circular := &Node{Name: "a", Next: &Node{Name: "b"}}
+	circular.Next.Next = circular
+
+	err := configor.New(configor.WithMaxDepth(10), configor.WithErrorCollection(true)).Load(circular)
+	if err == nil || !strings.Contains(err.Error(), "exceeds the max depth 10") {
+		t.Errorf("Should got error when fields are deeper than max depth, but got %v", err)
+	}
+
+	config := generateDefaultConfig()
+	if err := configor.New(configor.WithMaxDepth(10)).Load(&config); err != nil {
+		t.Errorf("No error should happen when fields are within max depth, but got %v", err)
+	}
+}
+
+func TestLoadMapOfStructs(t *testing.T) {
+	type Server struct {
+		Host string
+		Port int `default:"80"`
+	}
+	type MapConfig struct {
+		Servers  map[string]Server
+		Replicas map[int]*Server
+	}
+
+	os.Setenv("CONFIGOR_SERVERS_PRIMARY_HOST", "primary.env")
+	defer os.Unsetenv("CONFIGOR_SERVERS_PRIMARY_HOST")
+	os.Setenv("CONFIGOR_REPLICAS_2_PORT", "8080")
+	defer os.Unsetenv("CONFIGOR_REPLICAS_2_PORT")
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("servers:\n  primary:\n    host: primary\n  backup:\n    host: backup\nreplicas:\n  1:\n    host: replica1\n  2:\n    host: replica2\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		var result MapConfig
+		if err := configor.Load(&result, file.Name()+".yml"); err != nil {
+			t.Errorf("No error should happen when load map of structs, but got %v", err)
+		}
+
+		expected := MapConfig{
+			Servers:  map[string]Server{"primary": {Host: "primary.env", Port: 80}, "backup": {Host: "backup", Port: 80}},
+			Replicas: map[int]*Server{1: {Host: "replica1", Port: 80}, 2: {Host: "replica2", Port: 8080}},
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("fields of structs in maps should be read from env and default tags, expect %#v, but got %#v", expected, result)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	type Server struct {
+		Host string
+		Port int
+	}
+	type MergeConfig struct {
+		Name    string
+		Debug   bool
+		Server  Server
+		Backup  *Server
+		Tags    []string
+		Options map[string]string
+	}
+
+	dst := MergeConfig{Name: "base", Server: Server{Host: "localhost", Port: 80}, Tags: []string{"a"}, Options: map[string]string{"a": "1"}}
+	err := configor.Merge(&dst,
+		MergeConfig{Server: Server{Port: 8080}, Backup: &Server{Host: "backup"}, Options: map[string]string{"b": "2"}},
+		&MergeConfig{Debug: true, Tags: []string{"b", "c"}, Backup: &Server{Port: 9090}},
+	)
+	if err != nil {
+		t.Errorf("No error should happen when merge, but got %v", err)
+	}
+
+	expected := MergeConfig{
+		Name:    "base",
+		Debug:   true,
+		Server:  Server{Host: "localhost", Port: 8080},
+		Backup:  &Server{Host: "backup", Port: 9090},
+		Tags:    []string{"b", "c"},
+		Options: map[string]string{"a": "1", "b": "2"},
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("non-zero fields should be merged in order, expect %#v, but got %#v", expected, dst)
+	}
+
+	if err := configor.Merge(&dst, Config{}); err == nil {
+		t.Errorf("Should got error when merge struct of different type")
+	}
+}
+
+func TestTestUtil(t *testing.T) {
+	testutil.WithEnv(t, map[string]string{"CONFIGOR_DB_NAME": "env_db"}, func() {
+		result := testutil.MustLoad[Config](t, "appname: testutil\ndb:\n  password: pass\n", "yml")
+		if result.APPName != "testutil" || result.DB.Name != "env_db" {
+			t.Errorf("configurations should be loaded with envs, but got %#v", result)
+		}
+	})
+
+	if file := testutil.TempConfig(t, "json", `{"APPName": "json"}`); filepath.Ext(file) != ".json" {
+		t.Errorf("temp config should have the format as extension, but got %v", file)
+	}
+}
+
+func TestDumpNilPointers(t *testing.T) {
+	type NilConfig struct {
+		Port    *int
+		Total   *big.Int
+		Timeout *time.Duration
+	}
+
+	expected := map[string]string{"CONFIGOR_PORT": "", "CONFIGOR_TOTAL": "", "CONFIGOR_TIMEOUT": ""}
+	if envs := configor.DumpEnv(&NilConfig{}); !reflect.DeepEqual(envs, expected) {
+		t.Errorf("nil pointers should be dumped as blank, but got %v", envs)
+	}
+	if values := configor.Flatten(&NilConfig{}); values["Port"] != "" || values["Total"] != "" || values["Timeout"] != "" {
+		t.Errorf("nil pointers should be flattened as blank, but got %v", values)
+	}
+}
+
+func TestDumpEnv(t *testing.T) {
+	type DumpConfig struct {
+		APPName string
+		Timeout time.Duration
+		Hosts   []string
+		DB      struct {
+			Port     int
+			Password string `env:"DBPassword"`
+		}
+	}
+
+	config := DumpConfig{APPName: "app", Timeout: time.Minute, Hosts: []string{"a", "b"}}
+	config.DB.Port = 3306
+	config.DB.Password = "pass"
+
+	expected := map[string]string{
+		"CONFIGOR_APPNAME": "app",
+		"CONFIGOR_TIMEOUT": "1m0s",
+		"CONFIGOR_HOSTS":   `["a","b"]`,
+		"CONFIGOR_DB_PORT": "3306",
+		"DBPassword":       "pass",
+	}
+	if envs := configor.DumpEnv(&config); !reflect.DeepEqual(envs, expected) {
+		t.Errorf("DumpEnv should return env names with values, expect %v, but got %v", expected, envs)
+	}
+
+	for name, value := range expected {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result DumpConfig
+	if err := configor.Load(&result); err != nil || !reflect.DeepEqual(result, config) {
+		t.Errorf("dumped env should be loaded back, but got %#v, %v", result, err)
+	}
+}
+
+func TestDescribeToWriter(t *testing.T) {
+	type DescribeConfig struct {
+		APPName  string `default:"configor" desc:"name of the application"`
+		Password string `secret:"true" required:"true"`
+		Mode     string `oneof:"debug release" default:"debug"`
+	}
+
+	os.Setenv("CONFIGOR_PASSWORD", "secret_password")
+	defer os.Unsetenv("CONFIGOR_PASSWORD")
+
+	loader := configor.New()
+	var result DescribeConfig
+	if err := loader.Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := loader.Describe(&buffer); err != nil {
+		t.Errorf("No error should happen when describe configurations, but got %v", err)
+	}
+
+	expected := "| Field | Env | Type | Value | Default | Required | Rules | Description |\n" +
+		"| --- | --- | --- | --- | --- | --- | --- | --- |\n" +
+		"| APPName | CONFIGOR_APPNAME | string | configor | configor |  |  | name of the application |\n" +
+		"| Password | CONFIGOR_PASSWORD | string | ****** |  | yes |  |  |\n" +
+		"| Mode | CONFIGOR_MODE | string | debug | debug |  | oneof=debug release |  |\n"
+	if buffer.String() != expected {
+		t.Errorf("Describe should write a markdown table, expect %q, but got %q", expected, buffer.String())
+	}
+
+	for _, format := range []string{"plain", "html"} {
+		var buffer bytes.Buffer
+		describer := configor.New(configor.WithDescribeFormat(format))
+		describer.Load(&result)
+		if err := describer.Describe(&buffer); err != nil || !strings.Contains(buffer.String(), "CONFIGOR_APPNAME") || strings.Contains(buffer.String(), "secret_password") {
+			t.Errorf("Describe should write %v table with secrets masked, but got %v, %v", format, buffer.String(), err)
+		}
+	}
+}
+
+func TestLoadBytesFromEnvironment(t *testing.T) {
+	type BytesConfig struct {
+		HexKey    []byte
+		Base64Key []byte
+		RawKey    []byte
+	}
+
+	var result BytesConfig
+	os.Setenv("CONFIGOR_HEXKEY", "hex:736563726574")
+	os.Setenv("CONFIGOR_BASE64KEY", "base64:c2VjcmV0")
+	os.Setenv("CONFIGOR_RAWKEY", "secret")
+	defer os.Setenv("CONFIGOR_HEXKEY", "")
+	defer os.Setenv("CONFIGOR_BASE64KEY", "")
+	defer os.Setenv("CONFIGOR_RAWKEY", "")
+	if err := configor.Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if string(result.HexKey) != "secret" || string(result.Base64Key) != "secret" || string(result.RawKey) != "secret" {
+		t.Errorf("bytes should be decoded from env, but got %#v", result)
+	}
+
+	os.Setenv("CONFIGOR_HEXKEY", "hex:invalid")
+	if err := configor.Load(&BytesConfig{}); err == nil {
+		t.Errorf("Should got error when load invalid hex bytes")
+	}
+}
+
+func TestLoadWithKeyNormalization(t *testing.T) {
+	type NormalizationConfig struct {
+		MaxConnections int
+		DB             struct {
+			UserName string
+		}
+		Servers []struct {
+			HostName string `json:"host"`
+		}
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("max-connections: 10\ndb:\n  user_name: root\nservers:\n- host-name: localhost\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		var result NormalizationConfig
+		if err := configor.New(configor.WithKeyNormalization(true)).Load(&result, file.Name()+".yml"); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		if result.MaxConnections != 10 || result.DB.UserName != "root" || len(result.Servers) != 1 || result.Servers[0].HostName != "localhost" {
+			t.Errorf("keys should be normalized to match struct fields, but got %#v", result)
+		}
+	}
+}
+
+func TestNormalizedDecodingKeepsConvertedTypes(t *testing.T) {
+	type NormalizedTypesConfig struct {
+		Timeout  time.Duration
+		Interval *time.Duration
+		Total    *big.Int
+		Started  time.Time
+	}
+
+	file := testutil.TempConfig(t, "yml", "timeout: 5s\ninterval: 1m\ntotal: '123456789012345678901234567890'\nstarted: 2024-01-02T03:04:05Z\n")
+	total, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	for name, option := range map[string]configor.Option{
+		"KeyNormalization": configor.WithKeyNormalization(true),
+		"DigitSeparator":   configor.WithDigitSeparator(","),
+		"TimeLayout":       configor.WithTimeLayout("2006-01-02 15:04:05"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var result NormalizedTypesConfig
+			if err := configor.New(option).Load(&result, file); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if result.Timeout != 5*time.Second || result.Interval == nil || *result.Interval != time.Minute || result.Total == nil || result.Total.Cmp(total) != 0 ||
+				!result.Started.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+				t.Errorf("values should be decoded like yaml, but got %#v", result)
+			}
+		})
+	}
+
+	// values decoded through json are converted with converters of their types
+	var endpoint struct{ Endpoint url.URL }
+	file = testutil.TempConfig(t, "yml", "endpoint: https://example.com/api\n")
+	if err := configor.New(configor.WithKeyNormalization(true)).Load(&endpoint, file); err != nil || endpoint.Endpoint.Host != "example.com" {
+		t.Errorf("url should be converted when normalizing keys, but got %#v, %v", endpoint, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			var result Config
+			c := configor.New()
+			if keys := c.Keys(); len(keys) != 0 {
+				t.Errorf("Keys should be blank before loading, but got %v", keys)
+			}
+
+			if err := c.Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			expected := []string{"APPName", "Contacts", "Contacts[0].Email", "Contacts[0].Name", "DB.Name", "DB.Password", "DB.Port", "DB.User"}
+			if keys := c.Keys(); !reflect.DeepEqual(keys, expected) {
+				t.Errorf("Keys should return all field paths, but got %v", keys)
+			}
+		}
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	type TLSConfig struct {
+		TLSEnabled bool
+		CertFile   string `required_if:"TLSEnabled"`
+		Mode       string
+		KeyFile    string `required_if:"Mode=secure"`
+	}
+
+	for _, test := range []struct {
+		content string
+		valid   bool
+	}{
+		{`{}`, true},
+		{`{"TLSEnabled": true}`, false},
+		{`{"TLSEnabled": true, "CertFile": "cert.pem"}`, true},
+		{`{"Mode": "insecure"}`, true},
+		{`{"Mode": "secure"}`, false},
+		{`{"Mode": "secure", "KeyFile": "key.pem"}`, true},
+	} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write([]byte(test.content))
+
+			if err := configor.Load(&TLSConfig{}, file.Name()); (err == nil) != test.valid {
+				t.Errorf("required_if should be validated for %v, but got %v", test.content, err)
+			}
+		}
+	}
+}
+
+func TestRequiredIfUnexportedField(t *testing.T) {
+	type UnexportedConfig struct {
+		enabled  bool
+		mode     *string
+		CertFile string `required_if:"enabled"`
+		KeyFile  string `required_if:"mode=secure"`
+	}
+
+	file := testutil.TempConfig(t, "json", `{}`)
+	if err := configor.Load(&UnexportedConfig{}, file); err == nil || !strings.Contains(err.Error(), "unexported") {
+		t.Errorf("Should got error when required_if references unexported fields, but got %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	type CloneConfig struct {
+		Config
+		Pattern  *regexp.Regexp
+		Location *time.Location
+		Handler  func() string
+		Labels   map[string][]string
+		Backend  interface{}
+	}
+
+	config := CloneConfig{
+		Config:   generateDefaultConfig(),
+		Pattern:  regexp.MustCompile("^config"),
+		Location: time.UTC,
+		Handler:  func() string { return "handler" },
+		Labels:   map[string][]string{"env": {"test"}},
+		Backend:  &struct{ Name string }{Name: "s3"},
+	}
+
+	cloned, err := configor.Clone(&config)
+	if err != nil {
+		t.Fatalf("No error should happen when clone configurations, but got %v", err)
+	}
+
+	result, ok := cloned.(*CloneConfig)
+	if !ok || result == &config {
+		t.Fatalf("Clone should return a new value with the same type, but got %#v", cloned)
+	}
+
+	if !reflect.DeepEqual(result.Config, config.Config) || result.Pattern.String() != "^config" || result.Location != time.UTC || result.Handler() != "handler" {
+		t.Errorf("cloned configuration should equal to the original one, but got %#v", result)
+	}
+
+	result.Contacts[0].Name = "cloned"
+	result.Labels["env"][0] = "cloned"
+	result.Backend.(*struct{ Name string }).Name = "cloned"
+	if config.Contacts[0].Name == "cloned" || config.Labels["env"][0] == "cloned" || config.Backend.(*struct{ Name string }).Name == "cloned" {
+		t.Errorf("cloned configuration should not share values with the original one")
+	}
+}
+
+func TestEditFile(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		content := "# application name\nappname: configor\n\ndb:\n  # database name\n  name: configor\n  port: 3306 # default port\ncustom: kept\n"
+		ioutil.WriteFile(file.Name()+".yml", []byte(content), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		err := configor.EditFile(file.Name()+".yml", &Config{}, func(config interface{}) error {
+			config.(*Config).DB.Port = 5432
+			return nil
+		})
+		if err != nil {
+			t.Errorf("No error should happen when edit file, but got %v", err)
+		}
+
+		edited, _ := ioutil.ReadFile(file.Name() + ".yml")
+		for _, expected := range []string{"# application name\nappname: configor\n", "  # database name\n  name: configor\n", "port: 5432 # default port\n", "custom: kept\n"} {
+			if !bytes.Contains(edited, []byte(expected)) {
+				t.Errorf("edited file should keep comments and values, expected %q in\n%s", expected, edited)
+			}
+		}
+	}
+}
+
+func TestIgnoreEnvErrors(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			os.Setenv("CONFIGOR_DB_PORT", "not_a_number")
+			defer os.Setenv("CONFIGOR_DB_PORT", "")
+
+			var result Config
+			if err := configor.Load(&result, file.Name()); err == nil {
+				t.Errorf("Should got error when load invalid env")
+			}
+
+			result = Config{}
+			if err := configor.New(configor.WithIgnoreEnvErrors(true)).Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when ignore env errors, but got %v", err)
+			}
+
+			if result.DB.Port != 3306 {
+				t.Errorf("value loaded from files should be kept when env is invalid, but got %v", result.DB.Port)
+			}
+		}
+	}
+}
+
+func TestLoadWithResult(t *testing.T) {
+	config := generateDefaultConfig()
+	config.DB.Port = 0
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			os.Setenv("CONFIGOR_DB_NAME", "db_name")
+			defer os.Setenv("CONFIGOR_DB_NAME", "")
+
+			var result Config
+			loadResult, err := configor.LoadWithResult(&result, file.Name())
+			if err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			for path, source := range map[string]configor.ValueSource{
+				"APPName": configor.ValueFromFile,
+				"DB.Name": configor.ValueFromEnv,
+				"DB.Port": configor.ValueFromDefault,
+			} {
+				if loadResult.Sources[path] != source {
+					t.Errorf("%v should be loaded from %v, but got %v", path, source, loadResult.Sources[path])
+				}
+			}
+
+			if !reflect.DeepEqual(loadResult.RedundantDefaults, []string{"APPName"}) {
+				t.Errorf("APPName set in file equals its default value, but got %v", loadResult.RedundantDefaults)
+			}
+		}
+	}
+}
+
+type AuthProvider interface {
+	Authenticate(token string) bool
+}
+
+type JWTProvider struct {
+	Secret    string
+	Algorithm string `default:"HS256"`
+}
+
+func (provider *JWTProvider) Authenticate(token string) bool {
+	return token == provider.Secret
+}
+
+func TestLoadInterfaceFieldWithRegisteredType(t *testing.T) {
+	configor.RegisterType("jwt", func() interface{} { return &JWTProvider{} })
+
+	type AuthConfig struct {
+		Auth AuthProvider `type:"jwt"`
+	}
+
+	for _, ext := range []string{".yml", ".json"} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			content := "auth:\n  secret: token\n"
+			if ext == ".json" {
+				content = `{"Auth": {"Secret": "token"}}`
+			}
+			ioutil.WriteFile(file.Name()+ext, []byte(content), 0644)
+			defer os.Remove(file.Name() + ext)
+
+			var result AuthConfig
+			if err := configor.Load(&result, file.Name()+ext); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if provider, ok := result.Auth.(*JWTProvider); !ok || !provider.Authenticate("token") || provider.Algorithm != "HS256" {
+				t.Errorf("interface field should be decoded with registered type, but got %#v", result.Auth)
+			}
+		}
+	}
+}
+
+type S3Backend struct {
+	Bucket string
+	Region string `default:"us-east-1"`
+}
+
+type GCSBackend struct {
+	Bucket  string
+	Project string
+}
+
+func TestLoadInterfaceFieldWithDiscriminator(t *testing.T) {
+	configor.RegisterType("s3", S3Backend{})
+	configor.RegisterType("gcs", &GCSBackend{})
+
+	type StorageConfig struct {
+		Backend interface{} `discriminator:"type"`
+	}
+
+	for content, expected := range map[string]interface{}{
+		"backend:\n  type: s3\n  bucket: assets\n":                  &S3Backend{Bucket: "assets", Region: "us-east-1"},
+		"backend:\n  type: gcs\n  bucket: assets\n  project: app\n": &GCSBackend{Bucket: "assets", Project: "app"},
+	} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			ioutil.WriteFile(file.Name()+".yml", []byte(content), 0644)
+			defer os.Remove(file.Name() + ".yml")
+
+			var result StorageConfig
+			if err := configor.Load(&result, file.Name()+".yml"); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if !reflect.DeepEqual(result.Backend, expected) {
+				t.Errorf("interface field should be decoded with the type in data, expect %#v, but got %#v", expected, result.Backend)
+			}
+		}
+	}
+
+	// keys are matched like yaml unless WithKeyNormalization is enabled
+	type PoolConfig struct {
+		Backend interface{} `discriminator:"type"`
+		MaxConn int         `yaml:"max_conn"`
+		MinConn int         `yaml:"min_conn"`
+	}
+	file := testutil.TempConfig(t, "yml", "backend:\n  type: s3\nMAX-CONN: 5\nmin_conn: 1\n")
+	var pool PoolConfig
+	if err := configor.Load(&pool, file); err != nil || pool.MaxConn != 0 || pool.MinConn != 1 {
+		t.Errorf("keys shouldn't be normalized without key normalization, but got %#v, %v", pool, err)
+	}
+
+	var normalized PoolConfig
+	if err := configor.New(configor.WithKeyNormalization(true)).Load(&normalized, file); err != nil || normalized.MaxConn != 5 || normalized.MinConn != 1 {
+		t.Errorf("keys should be normalized with key normalization, but got %#v, %v", normalized, err)
+	}
+}
+
+type OAuthConfig struct {
+	Type     string
+	ClientID string
+}
+
+func (provider *OAuthConfig) Authenticate(token string) bool {
+	return token != ""
+}
+
+type SAMLConfig struct {
+	Type        string
+	MetadataURL string
+}
+
+func (provider *SAMLConfig) Authenticate(token string) bool {
+	return token != ""
+}
+
+func TestLoadInterfaceFieldWithOneOfStruct(t *testing.T) {
+	configor.RegisterType("AuthProvider.oauth", OAuthConfig{})
+	configor.RegisterType("AuthProvider.saml", SAMLConfig{})
+
+	type AppConfig struct {
+		Auth AuthProvider `oneof_struct:"type:AuthProvider"`
+	}
+
+	for content, expected := range map[string]interface{}{
+		`{"auth": {"type": "oauth", "clientid": "app"}}`:                    &OAuthConfig{Type: "oauth", ClientID: "app"},
+		`{"auth": {"type": "saml", "metadataurl": "https://idp/metadata"}}`: &SAMLConfig{Type: "saml", MetadataURL: "https://idp/metadata"},
+	} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			ioutil.WriteFile(file.Name()+".json", []byte(content), 0644)
+			defer os.Remove(file.Name() + ".json")
+
+			var result AppConfig
+			if err := configor.Load(&result, file.Name()+".json"); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if !reflect.DeepEqual(result.Auth, expected) {
+				t.Errorf("interface field should be decoded with the type in its group, expect %#v, but got %#v", expected, result.Auth)
+			}
+		}
+	}
+}
+
+func TestOverwriteConfigurationWithEnvNameFunc(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+			var result Config
+			os.Setenv("MYSERVICE__DB__NAME", "db_name")
+			defer os.Setenv("MYSERVICE__DB__NAME", "")
+
+			envNameFunc := func(path []string) string {
+				return strings.ToUpper("myservice__" + strings.Join(path, "__"))
+			}
+			if err := configor.New(configor.WithEnvNameFunc(envNameFunc)).Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if result.DB.Name != "db_name" {
+				t.Errorf("env name should be generated with the custom function, but got %v", result.DB.Name)
+			}
+		}
+	}
+}
+
+func TestOverwriteConfigurationWithIgnorePrefix(t *testing.T) {
+	type ExternalConfig struct {
+		Host string
+	}
+
+	type IgnorePrefixConfig struct {
+		Redis    ExternalConfig `ignore_prefix:"true"`
+		Database ExternalConfig `ignore_prefix:"true" env:"PG"`
+		Cache    ExternalConfig
+	}
+
+	os.Setenv("HOST", "redis_host")
+	defer os.Unsetenv("HOST")
+	os.Setenv("PG_HOST", "pg_host")
+	defer os.Unsetenv("PG_HOST")
+	os.Setenv("CONFIGOR_CACHE_HOST", "cache_host")
+	defer os.Unsetenv("CONFIGOR_CACHE_HOST")
+
+	var result IgnorePrefixConfig
+	if err := configor.Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Redis.Host != "redis_host" || result.Database.Host != "pg_host" || result.Cache.Host != "cache_host" {
+		t.Errorf("prefix chain should be broken by ignore_prefix tag, but got %#v", result)
+	}
+}
+
+func TestOverwriteConfigurationWithExplicitEnvOnly(t *testing.T) {
+	type ExplicitConfig struct {
+		APPName string
+		Port    int `env:"CONFIGOR_TEST_EXPLICIT_PORT"`
+	}
+
+	os.Setenv("CONFIGOR_APPNAME", "guessed")
+	defer os.Unsetenv("CONFIGOR_APPNAME")
+	os.Setenv("CONFIGOR_TEST_EXPLICIT_PORT", "8080")
+	defer os.Unsetenv("CONFIGOR_TEST_EXPLICIT_PORT")
+
+	var result ExplicitConfig
+	if err := configor.New(configor.WithExplicitEnvOnly(true)).Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.APPName != "" || result.Port != 8080 {
+		t.Errorf("only fields tagged with env should be read from env, but got %#v", result)
+	}
+}
+
+func TestOverwriteConfigurationWithNamingConvention(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			for convention, envName := range map[configor.NamingConvention]string{
+				configor.NamingScreamingSnake: "CONFIGOR_DB_NAME",
+				configor.NamingKebab:          "CONFIGOR-DB-NAME",
+				configor.NamingCamel:          "ConfigorDBName",
+				configor.NamingLower:          "configor_db_name",
+			} {
+				os.Setenv(envName, "db_name")
+
+				var result Config
+				if err := configor.New(configor.WithNamingConvention(convention)).Load(&result, file.Name()); err != nil {
+					t.Errorf("No error should happen when load configurations, but got %v", err)
+				}
+
+				if result.DB.Name != "db_name" {
+					t.Errorf("env name should be %v, but got %v", envName, result.DB.Name)
+				}
+				os.Unsetenv(envName)
+			}
+		}
+	}
+}
+
+func TestOverwriteConfigurationWithEnvVarSeparator(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			for sep, envName := range map[string]string{"__": "CONFIGOR__DB__NAME", ".": "CONFIGOR.DB.NAME"} {
+				os.Setenv(envName, "db_name")
+
+				var result Config
+				if err := configor.New(configor.WithEnvVarSeparator(sep)).Load(&result, file.Name()); err != nil {
+					t.Errorf("No error should happen when load configurations, but got %v", err)
+				}
+
+				if result.DB.Name != "db_name" {
+					t.Errorf("env name should be %v, but got %v", envName, result.DB.Name)
+				}
+				os.Unsetenv(envName)
+			}
+		}
+	}
+}
+
+func TestLoadProjected(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "configor_appname"), []byte("projected\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "CONFIGOR_DB_NAME"), []byte("db_name"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "DBPassword"), []byte("db_password\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "..data"), []byte("ignored"), 0644)
+
+	var result Config
+	if err := configor.LoadProjected(&result, dir); err != nil {
+		t.Errorf("No error should happen when load projected configurations, but got %v", err)
+	}
+
+	if result.APPName != "projected" || result.DB.Name != "db_name" || result.DB.Password != "db_password" || result.DB.Port != 3306 {
+		t.Errorf("configurations should be loaded from key files, but got %#v", result)
+	}
+}
+
+func TestMustLoad(t *testing.T) {
+	config := generateDefaultConfig()
+	config.DB.Password = ""
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			os.Setenv("DBPassword", "")
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "Password is required") {
+					t.Errorf("MustLoad should panic with the error message, but got %v", r)
+				}
+			}()
+
+			var result Config
+			configor.MustLoad(&result, file.Name())
+		}
+	}
+}
+
+func TestLoadWithGracefulDegradation(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			os.Setenv("CONFIGOR_DB_PORT", "not_a_number")
+			defer os.Setenv("CONFIGOR_DB_PORT", "")
+
+			var result Config
+			if err := configor.Load(&result, file.Name(), "/tmp/configor_missing.yml"); err == nil {
+				t.Errorf("Should got error when load missing files")
+			}
+
+			result = Config{}
+			warnings, err := configor.New(configor.WithGracefulDegradation(true)).LoadWithWarnings(&result, file.Name(), "/tmp/configor_missing.yml")
+			if err != nil {
+				t.Errorf("No error should happen with graceful degradation, but got %v", err)
+			}
+
+			if len(warnings.Errors) != 2 || result.DB.Port != 3306 {
+				t.Errorf("missing files and invalid envs should be collected as warnings, but got %v", warnings)
+			}
+		}
+	}
+}
+
+func TestFilePrecedence(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".first.yml", []byte("appname: first\ndb:\n  name: first\n  password: pass\n"), 0644)
+		defer os.Remove(file.Name() + ".first.yml")
+		ioutil.WriteFile(file.Name()+".last.yml", []byte("appname: last\ndb:\n  user: last\n"), 0644)
+		defer os.Remove(file.Name() + ".last.yml")
+
+		for precedence, expected := range map[configor.FilePrecedence]string{configor.FirstFileWins: "first", configor.LastFileWins: "last"} {
+			var result Config
+			if err := configor.New(configor.WithFilePrecedence(precedence)).Load(&result, file.Name()+".first.yml", file.Name()+".last.yml"); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			if result.APPName != expected || result.DB.Name != "first" || result.DB.User != "last" {
+				t.Errorf("%v file should win, but got %#v", expected, result)
+			}
+		}
+	}
+}
+
+func TestLoadWithGlob(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "01-base.yml"), []byte("appname: base\ndb:\n  name: base\n  password: pass\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "02-override.yml"), []byte("appname: override\ndb:\n  user: override\n"), 0644)
+
+		var result Config
+		if err := configor.New(configor.WithFilePrecedence(configor.LastFileWins)).Load(&result, filepath.Join(dir, "*.yml")); err != nil {
+			t.Errorf("No error should happen when load configurations with glob, but got %v", err)
+		}
+
+		if result.APPName != "override" || result.DB.Name != "base" || result.DB.User != "override" {
+			t.Errorf("matched files should be loaded in order, but got %#v", result)
+		}
+
+		var empty Config
+		warnings, err := configor.New().LoadWithWarnings(&empty, filepath.Join(dir, "*.json"), filepath.Join(dir, "01-base.yml"))
+		if err != nil {
+			t.Errorf("No error should happen when glob matches nothing, but got %v", err)
+		}
+
+		if len(warnings.Errors) != 1 {
+			t.Errorf("glob matches nothing should be warned, but got %v", warnings)
+		}
+	}
+}
+
+type hexColor uint32
+
+func TestLoadWithConverters(t *testing.T) {
+	configor.RegisterConverter(reflect.TypeOf(hexColor(0)), func(value string) (reflect.Value, error) {
+		color, err := strconv.ParseUint(strings.TrimPrefix(value, "#"), 16, 32)
+		return reflect.ValueOf(hexColor(color)), err
+	})
+
+	type ConverterConfig struct {
+		Timeout  time.Duration `default:"1m30s"`
+		Deadline time.Time     `default:"2024-01-02T15:04:05Z"`
+		IP       net.IP        `default:"10.0.0.1"`
+		Endpoint url.URL       `default:"https://example.com/api"`
+		Proxy    *url.URL
+		Color    hexColor `default:"#ff0000"`
+	}
+
+	os.Setenv("CONFIGOR_PROXY", "http://proxy:8080")
+	defer os.Unsetenv("CONFIGOR_PROXY")
+
+	var result ConverterConfig
+	if err := configor.Load(&result); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Timeout != 90*time.Second || !result.Deadline.Equal(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)) ||
+		!result.IP.Equal(net.ParseIP("10.0.0.1")) || result.Endpoint.Host != "example.com" || result.Proxy == nil ||
+		result.Proxy.Host != "proxy:8080" || result.Color != 0xff0000 {
+		t.Errorf("values should be parsed with converters, but got %#v", result)
+	}
+}
+
+func TestLoadTimeAcrossFormats(t *testing.T) {
+	type TimeConfig struct {
+		StartAt time.Time
+	}
+
+	// time-zone-naive timestamps should be in UTC regardless of the local time zone
+	local := time.Local
+	time.Local = time.FixedZone("UTC+8", 8*60*60)
+	defer func() { time.Local = local }()
+
+	expected := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	for ext, content := range map[string]string{
+		".yml":  "startat: 2024-01-02 15:04:05",
+		".toml": "StartAt = 2024-01-02T15:04:05",
+		".json": `{"StartAt": "2024-01-02 15:04:05"}`,
+	} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			ioutil.WriteFile(file.Name()+ext, []byte(content), 0644)
+			defer os.Remove(file.Name() + ext)
+
+			var result TimeConfig
+			if err := configor.New(configor.WithTimeLayout("2006-01-02 15:04:05")).Load(&result, file.Name()+ext); err != nil {
+				t.Errorf("No error should happen when load %v, but got %v", ext, err)
+			}
+
+			if !result.StartAt.Equal(expected) || result.StartAt.Location() != time.UTC {
+				t.Errorf("timestamp in %v should be %v, but got %v", ext, expected, result.StartAt)
+			}
+
+			var direct TimeConfig
+			if ext != ".json" {
+				if err := configor.Load(&direct, file.Name()+ext); err != nil || !direct.StartAt.Equal(expected) {
+					t.Errorf("timestamp in %v should be %v without layout, but got %v, %v", ext, expected, direct.StartAt, err)
+				}
+			}
+		}
+	}
+
+	os.Setenv("CONFIGOR_STARTAT", "2024-01-02 15:04:05")
+	defer os.Unsetenv("CONFIGOR_STARTAT")
+
+	var result TimeConfig
+	if err := configor.Load(&result); err != nil || !result.StartAt.Equal(expected) {
+		t.Errorf("timestamp from env should be %v, but got %v, %v", expected, result.StartAt, err)
+	}
+
+	// fields are decoded like yaml with the layout, custom unmarshalers are called and keys are not normalized
+	type ModeTimeConfig struct {
+		Mode    upperMode
+		MaxConn int `yaml:"max_conn"`
+		StartAt time.Time
+	}
+	file := testutil.TempConfig(t, "yml", "mode: fast\nMAX-CONN: 5\nstartat: 2024-01-02 15:04:05\n")
+	var modeConfig ModeTimeConfig
+	if err := configor.New(configor.WithTimeLayout("2006-01-02 15:04:05")).Load(&modeConfig, file); err != nil {
+		t.Errorf("No error should happen when load yaml with time layout, but got %v", err)
+	}
+	if modeConfig.Mode != "FAST" || modeConfig.MaxConn != 0 || !modeConfig.StartAt.Equal(expected) {
+		t.Errorf("yaml should be decoded as is except timestamps, but got %#v", modeConfig)
+	}
+}
+
+func TestLoadTOMLLocalDateAndTime(t *testing.T) {
+	type ScheduleConfig struct {
+		StartDate  time.Time
+		DailyAt    time.Time
+		EndDate    time.Time `default:"2024-12-31"`
+		ReportAt   time.Time `default:"18:30:00"`
+		ReminderAt time.Time
+		DeployAt   time.Time
+	}
+
+	expected := ScheduleConfig{
+		StartDate:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		DailyAt:    time.Date(0, 1, 1, 7, 30, 0, 0, time.UTC),
+		EndDate:    time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		ReportAt:   time.Date(0, 1, 1, 18, 30, 0, 0, time.UTC),
+		ReminderAt: time.Date(0, 1, 1, 9, 15, 30, 0, time.UTC),
+		DeployAt:   time.Date(2024, 1, 2, 7, 4, 5, 0, time.UTC),
+	}
+
+	os.Setenv("CONFIGOR_REMINDERAT", "09:15:30")
+	defer os.Unsetenv("CONFIGOR_REMINDERAT")
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".toml", []byte("StartDate = 2024-01-01\nDailyAt = 07:30:00\nDeployAt = 2024-01-02T15:04:05+08:00\n"), 0644)
+		defer os.Remove(file.Name() + ".toml")
+
+		for _, loader := range []*configor.Configor{configor.New(), configor.New(configor.WithKeyNormalization(true))} {
+			var result ScheduleConfig
+			if err := loader.Load(&result, file.Name()+".toml"); err != nil {
+				t.Errorf("No error should happen when load TOML local date and time, but got %v", err)
+			}
+
+			if !result.DeployAt.Equal(expected.DeployAt) {
+				t.Errorf("datetimes with offset shouldn't be changed, expect %v, but got %v", expected.DeployAt, result.DeployAt)
+			}
+
+			result.DeployAt = expected.DeployAt
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("local dates and times should be in UTC, expect %v, but got %v", expected, result)
+			}
+		}
+	}
+}
+
+type upperMode string
+
+func (mode *upperMode) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	*mode = upperMode(strings.ToUpper(value))
+	return nil
+}
+
+func TestLoadNumbersWithDigitSeparator(t *testing.T) {
+	type LimitConfig struct {
+		MaxBytes    int64 `default:"1,000,000"`
+		MaxRequests uint  `env:"CONFIGOR_TEST_MAX_REQUESTS"`
+		Ratio       float64
+		Name        string
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".json", []byte(`{"Ratio": "1,000.5", "Name": "1,000"}`), 0644)
+		defer os.Remove(file.Name() + ".json")
+
+		os.Setenv("CONFIGOR_TEST_MAX_REQUESTS", "10,000")
+		defer os.Unsetenv("CONFIGOR_TEST_MAX_REQUESTS")
+
+		var result LimitConfig
+		if err := configor.New(configor.WithDigitSeparator(",")).Load(&result, file.Name()+".json"); err != nil {
+			t.Errorf("No error should happen when load numbers with digit separator, but got %v", err)
+		}
+
+		expected := LimitConfig{MaxBytes: 1000000, MaxRequests: 10000, Ratio: 1000.5, Name: "1,000"}
+		if result != expected {
+			t.Errorf("digit separator should be stripped from numbers only, expect %#v, but got %#v", expected, result)
+		}
+	}
+
+	// fields are decoded like yaml with the separator, custom unmarshalers are called and keys are not normalized
+	type ModeConfig struct {
+		Mode    upperMode
+		MaxConn int `yaml:"max_conn"`
+		Limit   int
+	}
+	file := testutil.TempConfig(t, "yml", "mode: fast\nMAX-CONN: 5\nlimit: 1,000\n")
+	var modeConfig ModeConfig
+	if err := configor.New(configor.WithDigitSeparator(",")).Load(&modeConfig, file); err != nil {
+		t.Errorf("No error should happen when load yaml with digit separator, but got %v", err)
+	}
+	if expected := (ModeConfig{Mode: "FAST", Limit: 1000}); modeConfig != expected {
+		t.Errorf("yaml should be decoded as is except numbers, expect %#v, but got %#v", expected, modeConfig)
+	}
+}
+
+func TestDefaultValueForEnvironment(t *testing.T) {
+	type EnvDefaultConfig struct {
+		Host string `default:"localhost" default_production:"db.prod.internal"`
+		Port int    `default:"3306"`
+	}
+
+	defer os.Setenv("CONFIGOR_ENV", "")
+	for env, expected := range map[string]string{"development": "localhost", "production": "db.prod.internal"} {
+		os.Setenv("CONFIGOR_ENV", env)
+
+		var result EnvDefaultConfig
+		if err := configor.Load(&result); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		if result.Host != expected || result.Port != 3306 {
+			t.Errorf("default value of %v should be %v, but got %#v", env, expected, result)
+		}
+	}
+}
+
+func TestLoadField(t *testing.T) {
+	type FieldConfig struct {
+		ConfigFile string `default:"config.yml"`
+		DB         struct {
+			Port int `required:"true"`
+		}
+		APPName string
+		Timeout time.Duration `max_duration:"1m"`
+	}
+
+	os.Setenv("CONFIGOR_DB_PORT", "5432")
+	defer os.Unsetenv("CONFIGOR_DB_PORT")
+	os.Setenv("CONFIGOR_APPNAME", "app")
+	defer os.Unsetenv("CONFIGOR_APPNAME")
+
+	var result FieldConfig
+	if err := configor.LoadField(&result, "CONFIGOR_CONFIGFILE"); err != nil || result.ConfigFile != "config.yml" {
+		t.Errorf("default value should be used for the field, but got %v, %v", result.ConfigFile, err)
+	}
+
+	if err := configor.LoadField(&result, "CONFIGOR_DB_PORT"); err != nil || result.DB.Port != 5432 {
+		t.Errorf("field should be loaded from env, but got %v, %v", result.DB.Port, err)
+	}
+
+	if result.APPName != "" {
+		t.Errorf("other fields shouldn't be loaded, but got %v", result.APPName)
+	}
+
+	// env key is matched case insensitively, the env is read by its name
+	if err := configor.LoadField(&result, "configor_appname"); err != nil || result.APPName != "app" {
+		t.Errorf("field should be loaded from env of the matched name, but got %v, %v", result.APPName, err)
+	}
+
+	os.Setenv("CONFIGOR_TIMEOUT", "5m")
+	defer os.Unsetenv("CONFIGOR_TIMEOUT")
+	if err := configor.LoadField(&result, "CONFIGOR_TIMEOUT"); err == nil || !strings.Contains(err.Error(), "should be at most 1m0s") {
+		t.Errorf("Should got error when field fails validation, but got %v", err)
+	}
+
+	os.Unsetenv("CONFIGOR_DB_PORT")
+	var blank FieldConfig
+	if err := configor.LoadField(&blank, "CONFIGOR_DB_PORT"); err == nil {
+		t.Errorf("should returns error if required field is blank")
+	}
+
+	if err := configor.LoadField(&blank, "APP_PORT", configor.WithPrefixes("APP")); err == nil {
+		t.Errorf("should returns error if no field found for the env")
+	}
+}
+
+func TestRequiredErrorWithFullPath(t *testing.T) {
+	type ServerConfig struct {
+		Servers []struct {
+			Host string `required:"true"`
+		}
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("servers:\n- host: a\n- host: b\n- port: 80\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		var result ServerConfig
+		if err := configor.Load(&result, file.Name()+".yml"); err == nil || err.Error() != "Servers[2].Host is required, but blank" {
+			t.Errorf("required error should include the full path, but got %v", err)
+		}
+	}
+}
+
+func TestLoadWithErrorCollection(t *testing.T) {
+	type CollectConfig struct {
+		Port     int    `env:"CONFIGOR_TEST_COLLECT_PORT"`
+		Password string `required:"true"`
+		TLS      bool   `default:"true"`
+		CertFile string `required_if:"TLS"`
+	}
+
+	os.Setenv("CONFIGOR_TEST_COLLECT_PORT", "invalid")
+	defer os.Unsetenv("CONFIGOR_TEST_COLLECT_PORT")
+
+	var result CollectConfig
+	err := configor.New(configor.WithErrorCollection(true)).Load(&result, "/tmp/configor_missing.yml")
+
+	var loadErrors configor.LoadErrors
+	if !errors.As(err, &loadErrors) {
+		t.Fatalf("errors should be collected as LoadErrors, but got %#v", err)
+	}
+
+	var phases []configor.LoadPhase
+	for _, err := range loadErrors {
+		phases = append(phases, err.(*configor.PhaseError).Phase)
+	}
+
+	expected := []configor.LoadPhase{configor.PhaseFile, configor.PhaseEnv, configor.PhaseRequired, configor.PhaseValidate}
+	if !reflect.DeepEqual(phases, expected) {
+		t.Errorf("errors of all phases should be collected, expect %v, but got %v", expected, loadErrors)
+	}
+
+	var configError *configor.ConfigError
+	if !errors.As(err, &configError) || configError.Field != "Port" {
+		t.Errorf("collected errors should be unwrapped, but got %#v", configError)
+	}
+
+	if err := configor.New().Load(&result, "/tmp/configor_missing.yml"); err == nil || strings.Contains(err.Error(), "[file]") {
+		t.Errorf("only the first error should be returned without error collection, but got %v", err)
+	}
+}
+
+func TestLoadWithProfile(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "worker.yml"), []byte("appname: worker\ndb:\n  name: jobs\n"), 0644)
+
+		configor.RegisterProfile("worker", configor.Profile{
+			Files:    []string{filepath.Join(dir, "worker.yml")},
+			Prefixes: []string{"WORKER"},
+			Required: map[string]bool{"DB.Password": false, "Contacts.Email": false},
+		})
+
+		os.Setenv("WORKER_DB_USER", "worker_user")
+		defer os.Unsetenv("WORKER_DB_USER")
+
+		var result Config
+		if err := configor.UseProfile("worker").Load(&result); err != nil {
+			t.Errorf("No error should happen when load with profile, but got %v", err)
+		}
+
+		if result.APPName != "worker" || result.DB.Name != "jobs" || result.DB.User != "worker_user" {
+			t.Errorf("configurations should be loaded with files and prefixes of the profile, but got %#v", result)
+		}
+
+		if err := configor.UseProfile("unknown").Load(&result); err == nil {
+			t.Errorf("should returns error if the profile is not registered")
+		}
+	}
+}
+
+func TestDurationRange(t *testing.T) {
+	type DurationConfig struct {
+		Timeout  time.Duration  `min_duration:"1s" max_duration:"1m"`
+		Interval *time.Duration `max_duration:"24h"`
+	}
+
+	for env, expected := range map[string]string{
+		"500ms": `invalid value "500ms" for Timeout: should be at least 1s`,
+		"2m":    `invalid value "2m0s" for Timeout: should be at most 1m0s`,
+		"30s":   "",
+	} {
+		os.Setenv("CONFIGOR_TIMEOUT", env)
+		os.Setenv("CONFIGOR_INTERVAL", "1h")
+
+		var result DurationConfig
+		err := configor.Load(&result)
+		if expected == "" && err != nil || expected != "" && (err == nil || err.Error() != expected) {
+			t.Errorf("duration %v should be validated with %q, but got %v", env, expected, err)
+		}
+
+		var configError *configor.ConfigError
+		if expected != "" && (!errors.As(err, &configError) || configError.Field != "Timeout") {
+			t.Errorf("ConfigError should be returned, but got %#v", err)
+		}
+	}
+	os.Unsetenv("CONFIGOR_TIMEOUT")
+	os.Unsetenv("CONFIGOR_INTERVAL")
+}
+
+func TestLoadWithDefaults(t *testing.T) {
+	defaults := generateDefaultConfig()
+	defaults.APPName = "defaults"
+	defaults.DB.Name = "defaults_db"
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("appname: file\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		os.Setenv("CONFIGOR_DB_NAME", "env_db")
+		defer os.Unsetenv("CONFIGOR_DB_NAME")
+
+		var result Config
+		if err := configor.LoadWithDefaults(&defaults, &result, file.Name()+".yml"); err != nil {
+			t.Errorf("No error should happen when load with defaults, but got %v", err)
+		}
+
+		if result.APPName != "file" || result.DB.Name != "env_db" || result.DB.Password != defaults.DB.Password || !reflect.DeepEqual(result.Contacts, defaults.Contacts) {
+			t.Errorf("files and env should override defaults, but got %#v", result)
+		}
+
+		result.Contacts[0].Name = "changed"
+		if defaults.Contacts[0].Name == "changed" || defaults.APPName != "defaults" {
+			t.Errorf("defaults should be deep copied, but got %#v", defaults)
+		}
+	}
+
+	if err := configor.LoadWithDefaults(Config{}, &struct{ Name string }{}); err == nil {
+		t.Errorf("Should got error when defaults is not the type of config")
+	}
+}
+
+func TestLoadPartial(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("appname: wizard\ncontacts:\n- name: jinzhu\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		var result Config
+		missing, err := configor.LoadPartial(&result, file.Name()+".yml")
+		if err != nil {
+			t.Errorf("No error should happen when load partial configurations, but got %v", err)
+		}
+
+		if expected := []string{"DB.Password", "Contacts[0].Email"}; !reflect.DeepEqual(missing, expected) {
+			t.Errorf("blank required fields should be reported, expect %v, but got %v", expected, missing)
+		}
+
+		if result.APPName != "wizard" || result.DB.User != "root" {
+			t.Errorf("configurations should be loaded partially, but got %#v", result)
+		}
+
+		os.Setenv("CONFIGOR_DB_PORT", "invalid")
+		defer os.Unsetenv("CONFIGOR_DB_PORT")
+		if _, err := configor.LoadPartial(&Config{}, file.Name()+".yml"); err == nil {
+			t.Errorf("Should got error other than blank required fields")
+		}
+	}
+}
+
+func TestLoadRequiring(t *testing.T) {
+	config := generateDefaultConfig()
+	config.DB.User = ""
+	config.Contacts[0].Name = ""
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			var result Config
+			err := configor.LoadRequiring(&result, []string{"Contacts.Name"}, file.Name())
+			if err == nil || err.Error() != "Contacts[0].Name is required, but blank" {
+				t.Errorf("Should got error when fields of required paths are blank, but got %v", err)
+			}
+
+			if err := configor.LoadRequiring(&result, []string{"DB.User"}, file.Name()); err != nil || result.DB.User != "root" {
+				t.Errorf("required fields with default tag should be set to the default value, but got %v, %v", result.DB.User, err)
+			}
+		}
+	}
+}
+
+func TestLoadConditionalFile(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte("appname: base\ndb:\n  name: base\n  password: pass\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "config.local.yml"), []byte("appname: local\n"), 0644)
+
+		loader := configor.New(
+			configor.WithConditionalFile(configor.EnvSet("CONFIGOR_TEST_DEVELOPER"), filepath.Join(dir, "config.local.yml")),
+			configor.WithConditionalFile(func() bool { return true }, filepath.Join(dir, "config.missing.yml")),
+		)
+
+		var result Config
+		if err := loader.Load(&result, filepath.Join(dir, "config.yml")); err != nil || result.APPName != "base" {
+			t.Errorf("conditional file shouldn't be loaded if predicate is false, but got %v, %v", result.APPName, err)
+		}
+
+		os.Setenv("CONFIGOR_TEST_DEVELOPER", "true")
+		defer os.Unsetenv("CONFIGOR_TEST_DEVELOPER")
+
+		var local Config
+		if err := loader.Load(&local, filepath.Join(dir, "config.yml")); err != nil || local.APPName != "local" || local.DB.Name != "base" {
+			t.Errorf("conditional file should be loaded with higher priority, but got %#v, %v", local, err)
+		}
+
+		var docker Config
+		err := configor.New(configor.WithConditionalFiles(
+			configor.ConditionalFile{Path: filepath.Join(dir, "config.local.yml"), Condition: func() bool { return false }},
+			configor.ConditionalFile{Path: filepath.Join(dir, "config.yml"), Condition: func() bool { return true }},
+		)).Load(&docker)
+		if err != nil || docker.APPName != "base" {
+			t.Errorf("only conditional files whose condition is true should be loaded, but got %v, %v", docker.APPName, err)
+		}
+	}
+}
+
+func TestLoadExampleWithVerbose(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "config.example.yml"), []byte("appname: example\ndb:\n  name: db\n  password: pass\n"), 0644)
+
+		var buffer bytes.Buffer
+		logger := log.New(&buffer, "", 0)
+
+		var result Config
+		if err := configor.New(configor.WithLogger(logger)).Load(&result, filepath.Join(dir, "config.yml")); err != nil || result.APPName != "example" {
+			t.Errorf("example file should be loaded, but got %v, %v", result.APPName, err)
+		}
+		if buffer.Len() != 0 {
+			t.Errorf("informational messages should be silent by default, but got %v", buffer.String())
+		}
+
+		if err := configor.New(configor.WithLogger(logger), configor.WithVerbose(true)).Load(&result, filepath.Join(dir, "config.yml")); err != nil {
+			t.Errorf("No error should happen when load example file, but got %v", err)
+		}
+		if !strings.Contains(buffer.String(), "using example file "+filepath.Join(dir, "config.example.yml")) {
+			t.Errorf("using example file should be logged if verbose, but got %v", buffer.String())
+		}
+	}
+}
+
+func TestDeprecatedFields(t *testing.T) {
+	type DeprecatedConfig struct {
+		Host    string `deprecated:"use Address instead" replaced_by:"Address"`
+		Address string `default:"localhost:80"`
+		Timeout int    `deprecated:"it's not used anymore"`
+		Retries int    `deprecated:"use MaxRetries instead"`
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("host: example.com:8080\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		os.Setenv("CONFIGOR_TIMEOUT", "10")
+		defer os.Unsetenv("CONFIGOR_TIMEOUT")
+
+		var buffer bytes.Buffer
+		var result DeprecatedConfig
+		warnings, err := configor.New(configor.WithLogger(log.New(&buffer, "", 0))).LoadWithWarnings(&result, file.Name()+".yml")
+		if err != nil {
+			t.Errorf("No error should happen when load deprecated fields, but got %v", err)
+		}
+
+		if result.Address != "example.com:8080" {
+			t.Errorf("value of deprecated field should be copied to its replacement, but got %v", result.Address)
+		}
+
+		expected := "Host is deprecated: use Address instead\nTimeout is deprecated: it's not used anymore"
+		if warnings.String() != expected || !strings.Contains(buffer.String(), "Host is deprecated") {
+			t.Errorf("deprecated fields set should be warned, expect %v, but got %v", expected, warnings.String())
+		}
+	}
+}
+
+func TestLoadWithMinVersion(t *testing.T) {
+	configor.RegisterMigration("1", "1.1", func(data map[string]interface{}) error {
+		data["appname"] = data["name"]
+		delete(data, "name")
+		return nil
+	})
+	configor.RegisterMigration("1.1", "2", func(data map[string]interface{}) error {
+		data["db"] = map[string]interface{}{"password": data["db_password"]}
+		delete(data, "db_password")
+		return nil
+	})
+
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "v1.yml"), []byte("configor_format_version: 1\nname: migrated\ndb_password: pass\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "v2.yml"), []byte("configor_format_version: 2\nappname: current\ndb:\n  password: pass\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "v3.yml"), []byte("configor_format_version: 3\n"), 0644)
+
+		var result Config
+		loadResult, err := configor.New(configor.WithMinVersion("2")).LoadWithResult(&result, filepath.Join(dir, "v1.yml"))
+		if err != nil || result.APPName != "migrated" || result.DB.Password != "pass" {
+			t.Errorf("configurations of lower version should be migrated, but got %#v, %v", result, err)
+		}
+
+		expected := []configor.FormatVersion{{File: filepath.Join(dir, "v1.yml"), Version: "1", MigratedTo: "2"}}
+		if !reflect.DeepEqual(loadResult.FormatVersions, expected) {
+			t.Errorf("format versions should be reported, expect %#v, but got %#v", expected, loadResult.FormatVersions)
+		}
+
+		var current Config
+		if err := configor.New(configor.WithMinVersion("2")).Load(&current, filepath.Join(dir, "v2.yml")); err != nil || current.APPName != "current" {
+			t.Errorf("configurations of current version should be loaded, but got %v, %v", current.APPName, err)
+		}
+
+		if err := configor.New(configor.WithMinVersion("2")).Load(&Config{}, filepath.Join(dir, "v3.yml")); err == nil || !strings.Contains(err.Error(), "newer than 2") {
+			t.Errorf("Should got error when format version is newer, but got %v", err)
+		}
+	}
+}
+
+func TestLoadWithVersionKey(t *testing.T) {
+	configor.RegisterMigration("10", "11", func(data map[string]interface{}) error {
+		data["appname"] = data["app"]
+		delete(data, "app")
+		return nil
+	})
+
+	loader := configor.New(configor.WithMinVersion("11"), configor.WithVersionKey("version"))
+	var result Config
+	file := testutil.TempConfig(t, "json", `{"version": 10, "app": "migrated", "db": {"password": "pass"}}`)
+	if loadResult, err := loader.LoadWithResult(&result, file); err != nil || result.APPName != "migrated" {
+		t.Errorf("configurations should be migrated by the version key, but got %#v, %v", result, err)
+	} else if len(loadResult.FormatVersions) != 1 || loadResult.FormatVersions[0].MigratedTo != "11" {
+		t.Errorf("format versions should be reported, but got %#v", loadResult.FormatVersions)
+	}
+
+	file = testutil.TempConfig(t, "json", `{"version": 9, "app": "old", "db": {"password": "pass"}}`)
+	if err := loader.Load(&Config{}, file); err == nil || !strings.Contains(err.Error(), "no migration from format version 9 to 11") {
+		t.Errorf("Should got error when migration step is missing, but got %v", err)
+	}
+}
+
+func TestLoadWithIntMigrations(t *testing.T) {
+	migrate := func(step string) func(data map[string]interface{}) error {
+		return func(data map[string]interface{}) error {
+			data["appname"] = fmt.Sprintf("%v>%v", data["appname"], step)
+			return nil
+		}
+	}
+	// registered out of order, chained by versions
+	configor.RegisterIntMigration(102, 103, migrate("103"))
+	configor.RegisterIntMigration(100, 101, migrate("101"))
+	configor.RegisterIntMigration(101, 102, migrate("102"))
+	configor.RegisterIntMigration(200, 199, migrate("199"))
+	configor.RegisterIntMigration(199, 200, migrate("200"))
+
+	loader := configor.New(configor.WithMinVersion("103"), configor.WithVersionKey("version"))
+	for version, expected := range map[string]string{"100": "v>101>102>103", "101": "v>102>103", "103": "v"} {
+		var result Config
+		file := testutil.TempConfig(t, "yaml", "version: "+version+"\nappname: v\ndb:\n  password: pass\n")
+		if loadResult, err := loader.LoadWithResult(&result, file); err != nil || result.APPName != expected {
+			t.Errorf("migrations from version %v should be chained in order, expect %v, but got %v, %v", version, expected, result.APPName, err)
+		} else if migratedTo := loadResult.FormatVersions[0].MigratedTo; (version == "103") != (migratedTo == "") || (version != "103" && migratedTo != "103") {
+			t.Errorf("migrated version should be reported for version %v, but got %v", version, migratedTo)
+		}
+	}
+
+	file := testutil.TempConfig(t, "yaml", "version: 99\nappname: v\n")
+	if err := loader.Load(&Config{}, file); err == nil || !strings.Contains(err.Error(), "no migration from format version 99 to 103") {
+		t.Errorf("Should got error when migration step is missing, but got %v", err)
+	}
+
+	file = testutil.TempConfig(t, "yaml", "version: 200\nappname: v\n")
+	if err := configor.New(configor.WithMinVersion("201"), configor.WithVersionKey("version")).Load(&Config{}, file); err == nil || !strings.Contains(err.Error(), "invalid migration") {
+		t.Errorf("Should got error when migration lowers the version, but got %v", err)
+	}
+}
+
+func TestField(t *testing.T) {
+	type Server struct {
+		Host string
+	}
+	type FieldConfig struct {
+		DB      *struct{ Port int }
+		Servers []Server
+		Labels  map[string]*Server
+	}
+
+	config := FieldConfig{DB: &struct{ Port int }{Port: 5432}, Servers: []Server{{Host: "a"}, {Host: "b"}}, Labels: map[string]*Server{"env": {Host: "c"}}}
+	for path, expected := range map[string]interface{}{"DB.Port": 5432, "servers[1].host": "b", "Servers.0.Host": "a", "Labels[env].Host": "c"} {
+		if field, err := configor.Field(&config, path); err != nil || field.Interface() != expected {
+			t.Errorf("%v should be %v, but got %v, %v", path, expected, field, err)
+		}
+	}
+
+	if field, err := configor.Field(&config, "DB.Port"); err != nil || !field.CanSet() {
+		t.Errorf("field should be settable, but got %v", err)
+	} else if field.SetInt(3306); config.DB.Port != 3306 {
+		t.Errorf("field should be set through the value, but got %v", config.DB.Port)
+	}
+
+	// pointer fields are returned as is
+	if field, err := configor.Field(&config, "DB"); err != nil || field.Interface() != config.DB {
+		t.Errorf("pointer field should be returned as is, but got %v, %v", field, err)
+	} else if field.Set(reflect.Zero(field.Type())); config.DB != nil {
+		t.Errorf("pointer field should be set through the value, but got %v", config.DB)
+	}
+	if field, err := configor.Field(&config, "DB"); err != nil || !field.IsNil() {
+		t.Errorf("nil pointer field should be returned, but got %v, %v", field, err)
+	}
+
+	for _, path := range []string{"DB.Port", "DB.Host", "Servers[2].Host", "Labels[prod].Host"} {
+		if _, err := configor.Field(&config, path); err == nil {
+			t.Errorf("Should got error when navigate to %v", path)
+		}
+	}
+}
+
+func TestLoadWithBaseDir(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		os.MkdirAll(filepath.Join(dir, "config"), 0755)
+		ioutil.WriteFile(filepath.Join(dir, "config", "app.yml"), []byte("appname: basedir\ndb:\n  name: db\n  password: pass\n"), 0644)
+
+		var result Config
+		if err := configor.New(configor.WithBaseDir(dir)).Load(&result, "config/app.yml"); err != nil || result.APPName != "basedir" {
+			t.Errorf("relative file should be resolved against base dir, but got %v, %v", result.APPName, err)
+		}
+
+		var absolute Config
+		if err := configor.New(configor.WithBaseDir("/nonexistent")).Load(&absolute, filepath.Join(dir, "config/app.yml")); err != nil || absolute.APPName != "basedir" {
+			t.Errorf("absolute file shouldn't be resolved against base dir, but got %v, %v", absolute.APPName, err)
+		}
+	}
+}
+
+func TestLoadWithEnvBlob(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("appname: file\ndb:\n  name: file_db\n  user: file_user\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+
+		os.Setenv("CONFIGOR_TEST_JSON", `{"APPName": "json", "DB": {"Name": "json_db", "Password": "pass"}}`)
+		defer os.Unsetenv("CONFIGOR_TEST_JSON")
+		os.Setenv("CONFIGOR_TEST_YAML", "db:\n  name: yaml_db\n  port: 5432\n")
+		defer os.Unsetenv("CONFIGOR_TEST_YAML")
+		os.Setenv("CONFIGOR_DB_NAME", "env_db")
+		defer os.Unsetenv("CONFIGOR_DB_NAME")
+
+		var result Config
+		err := configor.New(configor.WithEnvJSON("CONFIGOR_TEST_JSON"), configor.WithEnvYAML("CONFIGOR_TEST_YAML"), configor.WithEnvJSON("CONFIGOR_TEST_UNSET")).Load(&result, file.Name()+".yml")
+		if err != nil {
+			t.Errorf("No error should happen when load with env blob, but got %v", err)
+		}
+
+		if result.APPName != "json" || result.DB.Name != "env_db" || result.DB.User != "file_user" || result.DB.Port != 5432 || result.DB.Password != "pass" {
+			t.Errorf("env blob should override files, and be overridden by env of fields, but got %#v", result)
+		}
+	}
+}
+
+func TestLoadWithEnvFile(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		file.Write([]byte("# database\nCONFIGOR_DB_NAME=envfile_db\nexport CONFIGOR_DB_USER=\"envfile user\"\nDBPassword='pass' # inline\n"))
+
+		os.Setenv("CONFIGOR_DB_USER", "process_user")
+		defer os.Setenv("CONFIGOR_DB_USER", "")
+
+		var result Config
+		if err := configor.New(configor.WithEnvFile(file.Name())).Load(&result); err != nil {
+			t.Errorf("No error should happen when load with env file, but got %v", err)
+		}
+
+		if result.DB.Name != "envfile_db" || result.DB.User != "envfile user" || result.DB.Password != "pass" {
+			t.Errorf("envs should be loaded from env file, but got %#v", result.DB)
+		}
+
+		if os.Getenv("CONFIGOR_DB_NAME") != "" {
+			t.Errorf("envs in env file shouldn't be set to process env")
+		}
+	}
+}
+
+func TestLoadWithEnvFileEnvironment(t *testing.T) {
+	if dir, err := ioutil.TempDir("/tmp", "configor"); err == nil {
+		defer os.RemoveAll(dir)
+		ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte("appname: base\ndb:\n  name: base\n  password: pass\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "config.production.yml"), []byte("db:\n  name: production\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, "config.local.yml"), []byte("appname: local\n"), 0644)
+		ioutil.WriteFile(filepath.Join(dir, ".env"), []byte("CONFIGOR_ENV=production\nCONFIGOR_ENV_PREFIX=MYAPP\nMYAPP_DB_USER=envfile_user\nCONFIGOR_DB_PORT=5432\nCONFIGOR_TEST_DEVELOPER=true\n"), 0644)
+
+		var result Config
+		err := configor.New(
+			configor.WithEnvFile(filepath.Join(dir, ".env")),
+			configor.WithEnvConditionalFile("CONFIGOR_TEST_DEVELOPER", filepath.Join(dir, "config.local.yml")),
+		).Load(&result, filepath.Join(dir, "config.yml"))
+		if err != nil {
+			t.Fatalf("No error should happen when load with env file, but got %v", err)
+		}
+
+		if result.DB.Name != "production" {
+			t.Errorf("environment should be read from env file, but got %v", result.DB.Name)
+		}
+		if result.DB.User != "envfile_user" || result.DB.Port == 5432 {
+			t.Errorf("env prefix should be read from env file, but got %#v", result.DB)
+		}
+		if result.APPName != "local" {
+			t.Errorf("conditional file should be loaded if its env is set in env file, but got %v", result.APPName)
+		}
+
+		var withoutEnvFile Config
+		err = configor.New(
+			configor.WithEnvConditionalFile("CONFIGOR_TEST_DEVELOPER", filepath.Join(dir, "config.local.yml")),
+		).Load(&withoutEnvFile, filepath.Join(dir, "config.yml"))
+		if err != nil || withoutEnvFile.DB.Name != "base" || withoutEnvFile.APPName != "base" {
+			t.Errorf("environment and conditional files shouldn't be affected without env file, but got %#v, %v", withoutEnvFile, err)
+		}
+	}
+}
+
+func TestUnmarshalSection(t *testing.T) {
+	config := generateDefaultConfig()
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			loader := configor.New()
+			var result Config
+			if err := loader.Load(&result, file.Name()); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+
+			var db struct {
+				Name string
+				Port uint
+			}
+			if err := loader.Unmarshal("db", &db); err != nil {
+				t.Errorf("No error should happen when unmarshal section, but got %v", err)
+			}
+
+			if db.Name != config.DB.Name || db.Port != config.DB.Port {
+				t.Errorf("section should be unmarshaled, but got %#v", db)
+			}
+
+			var contact struct{ Email string }
+			if err := loader.Unmarshal("contacts[0]", &contact); err != nil || contact.Email != config.Contacts[0].Email {
+				t.Errorf("slice element should be unmarshaled, but got %#v, %v", contact, err)
+			}
+
+			if err := loader.Unmarshal("db.missing", &db); err == nil {
+				t.Errorf("should returns error if the section not found")
+			}
+		}
+	}
+}
+
+type mapResolver map[string]string
+
+func (resolver mapResolver) Resolve(ref string) (string, error) {
+	if value, ok := resolver[ref]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("secret %v not found", ref)
+}
+
+func TestSecretResolver(t *testing.T) {
+	type SecretConfig struct {
+		Password string `vault:"secret/data/myapp#password"`
+		Token    string `vault:"secret/data/myapp#token" default:"default_token"`
+		Port     int    `vault:"secret/data/myapp#port"`
+	}
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		file.Write([]byte(`{"Token": "file_token"}`))
+
+		resolver := mapResolver{"secret/data/myapp#password": "secret", "secret/data/myapp#token": "vault_token", "secret/data/myapp#port": "5432"}
+		var result SecretConfig
+		if err := configor.New(configor.WithSecretResolver("vault", resolver)).Load(&result, file.Name()); err != nil {
+			t.Errorf("No error should happen when load configurations, but got %v", err)
+		}
+
+		if result.Password != "secret" || result.Token != "file_token" || result.Port != 5432 {
+			t.Errorf("blank fields should be resolved with the secret resolver, but got %#v", result)
+		}
+
+		delete(resolver, "secret/data/myapp#password")
+		if err := configor.New(configor.WithSecretResolver("vault", resolver)).Load(&SecretConfig{}, file.Name()); err == nil {
+			t.Errorf("Should got error when failed to resolve secrets")
+		}
+	}
+}
+
+func TestOverrideMiddleware(t *testing.T) {
+	config := generateDefaultConfig()
+	handler := configor.OverrideMiddleware(&config, "X-Config")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(configor.FromContext(r.Context()).(*Config).APPName))
+	}))
+
+	for header, expected := range map[string]string{
+		"": "configor",
+		base64.StdEncoding.EncodeToString([]byte(`{"APPName": "overridden"}`)): "overridden",
+		base64.StdEncoding.EncodeToString([]byte("appname: overridden_yaml")):  "overridden_yaml",
+	} {
+		request := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			request.Header.Set("X-Config", header)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Body.String() != expected {
+			t.Errorf("configuration should be overridden from request header, expected %v, but got %v", expected, recorder.Body.String())
+		}
+	}
+
+	if config.APPName != "configor" {
+		t.Errorf("base configuration should not be changed, but got %v", config.APPName)
+	}
+
+	request := httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Config", "invalid base64")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("invalid overrides should be rejected, but got %v", recorder.Code)
+	}
+
+	// details of errors are logged rather than returned to clients
+	logger := &bufferLogger{}
+	handler = configor.New(configor.WithLogger(logger)).OverrideMiddleware(&config, "X-Config")(http.NotFoundHandler())
+	request = httptest.NewRequest("GET", "/", nil)
+	request.Header.Set("X-Config", base64.StdEncoding.EncodeToString([]byte(`{"DB": {"Port": "secret_value"}}`)))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest || strings.Contains(recorder.Body.String(), "secret_value") || strings.Contains(recorder.Body.String(), "Port") {
+		t.Errorf("invalid overrides should be rejected with a generic message, but got %v %v", recorder.Code, recorder.Body.String())
+	}
+	if !strings.Contains(logger.String(), "invalid configuration override") {
+		t.Errorf("details of invalid overrides should be logged, but got %v", logger.String())
+	}
+}
+
+func TestLoadFromRequestAtomically(t *testing.T) {
+	config := generateDefaultConfig()
+	request := httptest.NewRequest("GET", "/", nil)
+	// APPName is decoded before the invalid port
+	request.Header.Set("X-Config", base64.StdEncoding.EncodeToString([]byte(`{"APPName": "overridden", "DB": {"Port": "http"}}`)))
+	if err := configor.LoadFromRequest(&config, request, "X-Config"); err == nil {
+		t.Errorf("Should got error when load invalid overlay")
+	}
+	if config.APPName != "configor" {
+		t.Errorf("config shouldn't be changed partially when failed to load overlay, but got %v", config.APPName)
+	}
+
+	request.Header.Set("X-Config", base64.StdEncoding.EncodeToString([]byte("appname: overridden\ndb:\n  port: 5432\n")))
+	if err := configor.LoadFromRequest(&config, request, "X-Config"); err != nil || config.APPName != "overridden" || config.DB.Port != 5432 || config.DB.Name != "configor" {
+		t.Errorf("overlay should be merged over config, but got %#v, %v", config, err)
+	}
+}
+
+func TestGetXMLName(t *testing.T) {
+	type XMLConfig struct {
+		Name  string `xml:"app_name,attr"`
+		Port  int
+		Skip  string `xml:"-"`
+		Inner string `xml:",chardata"`
+	}
+
+	typ := reflect.TypeOf(XMLConfig{})
+	for name, expected := range map[string]string{"Name": "app_name", "Port": "port", "Skip": "skip", "Inner": "inner"} {
+		field, _ := typ.FieldByName(name)
+		if result := configor.GetXMLName(field); result != expected {
+			t.Errorf("xml name of %v should be %v, but got %v", name, expected, result)
+		}
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"config.yml", "config.production.yml", "config.staging.yml", "config.example.yml", "config.test.json", "database.qa.yml"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte("appname: configor"), 0644)
+	}
+
+	if envs := configor.Environments(filepath.Join(dir, "config.yml")); !reflect.DeepEqual(envs, []string{"production", "staging"}) {
+		t.Errorf("environments should be found from configuration files, but got %v", envs)
+	}
+
+	os.Setenv("CONFIGOR_ENV", "staging")
+	defer os.Setenv("CONFIGOR_ENV", "")
+	if !configor.IsEnv("staging") || configor.IsEnv("production") {
+		t.Errorf("IsEnv should check the current environment")
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	type OverrideConfig struct {
+		Config
+		Labels  map[string]string
+		Servers []*struct {
+			Host string
+			Port int
+		}
+	}
+
+	result := OverrideConfig{Config: generateDefaultConfig()}
+	err := configor.ApplyOverrides(&result, []string{
+		"appname=overridden",
+		"db.port = 5432",
+		`contacts[0].name="Jinzhu Zhang"`,
+		"labels.env='prod'",
+		"servers[1].host=localhost",
+		"servers.1.port=8080",
+	})
+	if err != nil {
+		t.Errorf("No error should happen when apply overrides, but got %v", err)
+	}
+
+	if result.APPName != "overridden" || result.DB.Port != 5432 || result.Contacts[0].Name != "Jinzhu Zhang" || result.Labels["env"] != "prod" {
+		t.Errorf("fields should be overridden, but got %#v", result)
+	}
+
+	if len(result.Servers) != 2 || result.Servers[1].Host != "localhost" || result.Servers[1].Port != 8080 {
+		t.Errorf("slice should be grown when overriding its elements, but got %#v", result.Servers)
+	}
+
+	for _, override := range []string{"unknown=value", "db.port=not_a_number", "db.port", "servers.99999999999.host=x", "servers.1025.host=x"} {
+		if err := configor.ApplyOverrides(&result, []string{override}); err == nil {
+			t.Errorf("Should got error when apply invalid override %v", override)
+		}
+	}
+	if len(result.Servers) != 2 {
+		t.Errorf("slice shouldn't be grown beyond the max slice index, but got %v elements", len(result.Servers))
+	}
+
+	if err := configor.New(configor.WithMaxSliceIndex(2)).ApplyOverrides(&result, []string{"servers.3.host=x"}); err == nil || !strings.Contains(err.Error(), "max slice index") {
+		t.Errorf("Should got error when index exceeds the configured max slice index, but got %v", err)
+	}
+}
+
+func TestLoadFromURLWithAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" || r.Header.Get("X-Env") != "test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"APPName": "remote", "DB": {"Password": "pass"}}`))
+	}))
+	defer server.Close()
+
+	var result Config
+	if err := configor.New(configor.WithHTTPAuth("user", "pass"), configor.WithHTTPHeader("X-Env", "test")).Load(&result, server.URL+"/config"); err != nil {
+		t.Errorf("No error should happen when load configurations from URL, but got %v", err)
+	}
+
+	if result.APPName != "remote" {
+		t.Errorf("configurations should be loaded from URL, but got %#v", result)
+	}
+
+	err := configor.New(configor.WithHTTPBearerToken("secret_token")).Load(&Config{}, server.URL+"/config")
+	if err == nil || strings.Contains(err.Error(), "secret_token") {
+		t.Errorf("Should got error without auth material when unauthorized, but got %v", err)
+	}
+}
+
+func TestLoadFromURLWithTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	err := configor.New(configor.WithHTTPTimeout(100*time.Millisecond)).Load(&Config{}, server.URL+"/config.json")
+	if err == nil || time.Since(start) > 5*time.Second {
+		t.Errorf("Should got error when fetching configurations timed out, but got %v after %v", err, time.Since(start))
+	}
+}
+
+type bufferLogger struct {
+	bytes.Buffer
+}
+
+func (logger *bufferLogger) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(&logger.Buffer, format+"\n", args...)
+}
+
+func TestLoadFromURLRedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/invalid.json" {
+			w.Write([]byte(`{"APPName": `))
+			return
+		}
+		w.Write([]byte(`{"APPName": "remote", "DB": {"Password": "pass"}}`))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	serverURL.User = url.UserPassword("user", "secret_password")
+
+	logger := &bufferLogger{}
+	loader := configor.New(configor.WithLogger(logger), configor.WithVerbose(true), configor.WithErrorCollection(true))
+	if err := loader.Load(&Config{}, serverURL.String()+"/config.json"); err != nil {
+		t.Errorf("No error should happen when load configurations from URL, but got %v", err)
+	}
+	if !strings.Contains(logger.String(), "Loaded configuration") || strings.Contains(logger.String(), "secret_password") {
+		t.Errorf("passwords in URLs should be redacted in logs, but got %v", logger.String())
+	}
+
+	err := loader.Load(&Config{}, serverURL.String()+"/invalid.json")
+	if err == nil || strings.Contains(err.Error(), "secret_password") || !strings.Contains(err.Error(), "xxxxx") {
+		t.Errorf("passwords in URLs should be redacted in errors, but got %v", err)
+	}
+}
+
+func TestApplyLinkerVariables(t *testing.T) {
+	type BuildConfig struct {
+		Version string
+		DB      struct {
+			Host string
+			Port int
+		}
+		Name string
+	}
+
+	ldflags := `-s -w -X main.Version=1.0.0 -X 'github.com/example/app/config.DB.Host=db.internal' --X=github.com/example/app/config.DB.Port=5432 ` +
+		`-X github.com/example/dep.Version=9.9.9 -X github.com/example/app/configs.Name=other -X "github.com/example/app/config.Name=app name"`
+
+	var result BuildConfig
+	result.Name = "kept"
+	if err := configor.ApplyLinkerVariables(&result, ldflags, "github.com/example/app/config"); err != nil {
+		t.Errorf("No error should happen when apply linker variables, but got %v", err)
+	}
+	if result.Version != "" || result.DB.Host != "db.internal" || result.DB.Port != 5432 || result.Name != "kept" {
+		t.Errorf("only blank fields should be set from variables of the package, but got %#v", result)
+	}
+
+	var mainResult BuildConfig
+	if err := configor.ApplyLinkerVariables(&mainResult, ldflags, ""); err != nil || mainResult.Version != "1.0.0" || mainResult.DB.Host != "" {
+		t.Errorf("variables of the main package should be used by default, but got %#v, %v", mainResult, err)
+	}
+
+	if err := configor.ApplyLinkerVariables(&BuildConfig{}, "-X main.DB.Port=http", ""); err == nil {
+		t.Errorf("Should got error when apply invalid linker variables")
+	}
+}
+
+type memorySource string
+
+func (source memorySource) Read() ([]byte, string, error) {
+	return []byte(source), "yaml", nil
+}
+
+func TestLoadSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"APPName": "remote", "DB": {"Name": "remote_db", "User": "remote_user"}}`))
+	}))
+	defer server.Close()
+
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		file.Write([]byte("appname: file\ndb:\n  name: file_db\n  password: pass\n"))
+
+		var result Config
+		err := configor.LoadSources(&result, memorySource("appname: memory\n"), configor.FileSource(file.Name()), configor.URLSource(server.URL+"/config.json"))
+		if err != nil {
+			t.Errorf("No error should happen when load from sources, but got %v", err)
+		}
+
+		if result.APPName != "memory" || result.DB.Name != "file_db" || result.DB.User != "remote_user" || result.DB.Password != "pass" {
+			t.Errorf("sources should be merged in order, but got %#v", result)
+		}
+	}
+
+	if err := configor.LoadSources(&Config{}, configor.FileSource("/tmp/configor_missing.yml")); err == nil {
+		t.Errorf("Should got error when file source is not found")
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	config := generateDefaultConfig()
+	config.DB.Name = ""
+
+	if bytes, err := json.Marshal(config); err == nil {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write(bytes)
+
+			os.Setenv("DBPassword", "")
+			os.Setenv("CONFIGOR_APPNAME", "env_app")
+			os.Setenv("CONFIGOR_DB_NAME", "env_db")
+			defer os.Setenv("CONFIGOR_APPNAME", "")
+			defer os.Setenv("CONFIGOR_DB_NAME", "")
+
+			for precedence, expected := range map[configor.ConfigPrecedence][2]string{
+				configor.PrecedenceFilesFirst: {"env_app", "env_db"},
+				configor.PrecedenceEnvFirst:   {"configor", "env_db"},
+				configor.PrecedenceEnvOnly:    {"env_app", "env_db"},
+				configor.PrecedenceFilesOnly:  {"configor", ""},
+			} {
+				var result Config
+				err := configor.New(configor.WithConfigPrecedence(precedence)).Load(&result, file.Name())
+				if precedence == configor.PrecedenceEnvOnly {
+					if err == nil || !strings.Contains(err.Error(), "is required") {
+						t.Errorf("files should be ignored with PrecedenceEnvOnly, but got %v", err)
+					}
+				} else if err != nil {
+					t.Errorf("No error should happen when load configurations, but got %v", err)
+				}
+
+				if result.APPName != expected[0] || result.DB.Name != expected[1] {
+					t.Errorf("precedence %v should load %v, but got %v, %v", precedence, expected, result.APPName, result.DB.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenSection(t *testing.T) {
+	if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+		defer file.Close()
+		defer os.Remove(file.Name())
+		ioutil.WriteFile(file.Name()+".yml", []byte("appname: configor\ndatabase:\n  host: localhost\n  replica:\n    host: replica\n"), 0644)
+		defer os.Remove(file.Name() + ".yml")
+		ioutil.WriteFile(file.Name()+".test.yml", []byte("database:\n  user: test\n"), 0644)
+		defer os.Remove(file.Name() + ".test.yml")
+
+		os.Setenv("CONFIGOR_ENV", "test")
+		defer os.Setenv("CONFIGOR_ENV", "")
+		os.Setenv("CONFIGOR_DATABASE_USER", "env_user")
+		defer os.Setenv("CONFIGOR_DATABASE_USER", "")
+
+		raw, err := configor.Open(file.Name() + ".yml")
+		if err != nil {
+			t.Fatalf("No error should happen when open configurations, but got %v", err)
+		}
+
+		type DatabaseConfig struct {
+			Host string
+			User string
+			Port int `default:"3306"`
+		}
+
+		var database DatabaseConfig
+		if err := raw.Section("database", &database); err != nil {
+			t.Errorf("No error should happen when decode section, but got %v", err)
+		}
+		if database != (DatabaseConfig{Host: "localhost", User: "env_user", Port: 3306}) {
+			t.Errorf("section should be decoded with env and default tags, but got %#v", database)
+		}
+
+		var replica DatabaseConfig
+		if err := raw.Section("Database.Replica", &replica); err != nil || replica.Host != "replica" {
+			t.Errorf("nested section should be decoded, but got %#v, %v", replica, err)
+		}
+	}
+}
+
+func TestSourceConstraints(t *testing.T) {
+	type SourceConfig struct {
+		Secret   string `source:"env"`
+		Endpoint string `source:"file"`
+	}
+
+	for _, test := range []struct {
+		content string
+		env     map[string]string
+		valid   bool
+	}{
+		{`{"Endpoint": "localhost"}`, map[string]string{"CONFIGOR_SECRET": "secret"}, true},
+		{`{"Endpoint": "localhost"}`, nil, false},
+		{`{"Secret": "secret"}`, map[string]string{"CONFIGOR_SECRET": "secret"}, true},
+		{`{"Secret": "secret"}`, nil, false},
+		{`{}`, map[string]string{"CONFIGOR_SECRET": "secret", "CONFIGOR_ENDPOINT": "localhost"}, false},
+	} {
+		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
+			defer file.Close()
+			defer os.Remove(file.Name())
+			file.Write([]byte(test.content))
+
+			for key, value := range test.env {
+				os.Setenv(key, value)
+			}
+
+			err := configor.Load(&SourceConfig{}, file.Name())
+			if (err == nil) != test.valid {
+				t.Errorf("source constraints should be checked for %v with env %v, but got %v", test.content, test.env, err)
+			}
+
+			for key := range test.env {
+				os.Setenv(key, "")
+			}
+		}
+	}
+}
+
+func TestLoadYAMLWithInclude(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "shared"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "config.yml"), []byte("appname: configor\ndb: !include shared/database.yml\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "shared", "database.yml"), []byte("name: included\npassword: !include password.yml\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "shared", "password.yml"), []byte("secret\n"), 0644)
+
+	var result Config
+	if err := configor.Load(&result, filepath.Join(dir, "config.yml")); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.DB.Name != "included" || result.DB.Password != "secret" {
+		t.Errorf("included files should be inlined, but got %#v", result.DB)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "shared", "password.yml"), []byte("!include ../config.yml\n"), 0644)
+	if err := configor.Load(&Config{}, filepath.Join(dir, "config.yml")); err == nil || !strings.Contains(err.Error(), "circular include") {
+		t.Errorf("Should got error when include files circularly, but got %v", err)
+	}
+}
+
+type SchemaNode struct {
+	Name string
+	Next *SchemaNode
+}
+
+func TestInspectSchema(t *testing.T) {
+	type InspectConfig struct {
+		APPName  string `default:"configor"`
+		Internal string `configor:"-"`
+		DB       struct {
+			Password string `required:"true" env:"DBPassword"`
+		}
+		Contacts []struct {
+			Email string
+		}
+		Root SchemaNode
+	}
+
+	schema, err := configor.InspectSchema(&InspectConfig{})
+	if err != nil {
+		t.Fatalf("No error should happen when inspect schema, but got %v", err)
+	}
+
+	expected := configor.Schema{Fields: []configor.SchemaField{
+		{Path: "APPName", EnvVar: "CONFIGOR_APPNAME", Type: "string", Default: "configor"},
+		{Path: "DB", EnvVar: "CONFIGOR_DB", Type: `struct { Password string "required:\"true\" env:\"DBPassword\"" }`, Children: []configor.SchemaField{
+			{Path: "DB.Password", EnvVar: "DBPassword", Type: "string", Required: true},
+		}},
+		{Path: "Contacts", EnvVar: "CONFIGOR_CONTACTS", Type: "[]struct { Email string }", Children: []configor.SchemaField{
+			{Path: "Contacts[*].Email", EnvVar: "CONFIGOR_CONTACTS_*_EMAIL", Type: "string"},
+		}},
+		{Path: "Root", EnvVar: "CONFIGOR_ROOT", Type: "configor_test.SchemaNode", Children: []configor.SchemaField{
+			{Path: "Root.Name", EnvVar: "CONFIGOR_ROOT_NAME", Type: "string"},
+			{Path: "Root.Next", EnvVar: "CONFIGOR_ROOT_NEXT", Type: "*configor_test.SchemaNode"},
+		}},
+	}}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("schema is not correct, expect %#v, but got %#v", expected, schema)
+	}
+
+	if js, err := json.Marshal(schema); err != nil || !strings.Contains(string(js), `"envVar":"CONFIGOR_CONTACTS_*_EMAIL"`) {
+		t.Errorf("schema should be serialized to json, but got %v, %v", string(js), err)
+	}
+	if ys, err := yaml.Marshal(schema); err != nil || !strings.Contains(string(ys), "path: DB.Password") {
+		t.Errorf("schema should be serialized to yaml, but got %v, %v", string(ys), err)
+	}
+
+	if _, err := configor.InspectSchema("config"); err == nil {
+		t.Errorf("Should got error when inspect schema of non-struct")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	type SchemaConfig struct {
+		APPName string        `default:"configor" desc:"application name"`
+		Mode    string        `oneof:"debug release"`
+		Timeout time.Duration `default:"5s"`
+		DB      struct {
+			Password string `required:"true" json:"password"`
+			Port     uint   `default:"3306"`
+		}
+		Hosts []string
+	}
+
+	bytes, err := configor.JSONSchema(&SchemaConfig{})
+	if err != nil {
+		t.Fatalf("No error should happen when generate json schema, but got %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(bytes, &schema); err != nil {
+		t.Fatalf("json schema should be valid json, but got %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	appName := properties["APPName"].(map[string]interface{})
+	if appName["type"] != "string" || appName["default"] != "configor" || appName["description"] != "application name" {
+		t.Errorf("schema of APPName is not correct, got %v", appName)
+	}
+
+	if mode := properties["Mode"].(map[string]interface{}); !reflect.DeepEqual(mode["enum"], []interface{}{"debug", "release"}) {
+		t.Errorf("schema of Mode should have enum, got %v", mode)
+	}
+
+	if timeout := properties["Timeout"].(map[string]interface{}); timeout["type"] != "string" || timeout["default"] != "5s" {
+		t.Errorf("schema of Timeout is not correct, got %v", timeout)
+	}
+
+	db := properties["DB"].(map[string]interface{})
+	if !reflect.DeepEqual(db["required"], []interface{}{"password"}) || db["properties"].(map[string]interface{})["Port"].(map[string]interface{})["default"] != float64(3306) {
+		t.Errorf("schema of DB is not correct, got %v", db)
+	}
+
+	if hosts := properties["Hosts"].(map[string]interface{}); hosts["type"] != "array" || hosts["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("schema of Hosts is not correct, got %v", hosts)
+	}
+}
+
+type RecursiveNode struct {
+	Name     string
+	Next     *RecursiveNode
+	Children []RecursiveNode
+}
+
+type RecursiveTree struct {
+	Root    RecursiveNode
+	Parent  *RecursiveTree
+	Skipped string `configor:"-"`
+}
+
+func TestJSONSchemaRecursiveTypes(t *testing.T) {
+	bytes, err := configor.JSONSchema(&RecursiveTree{})
+	if err != nil {
+		t.Fatalf("No error should happen when generate json schema of recursive types, but got %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(bytes, &schema); err != nil {
+		t.Fatalf("json schema should be valid json, but got %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	if _, ok := properties["Skipped"]; ok {
+		t.Errorf("fields tagged with configor:\"-\" should be skipped, but got %v", properties)
+	}
+	if parent := properties["Parent"].(map[string]interface{}); parent["$ref"] != "#" {
+		t.Errorf("recursive root type should be referenced as #, but got %v", parent)
+	}
+
+	ref := "#/definitions/configor_test.RecursiveNode"
+	if root := properties["Root"].(map[string]interface{}); root["$ref"] != ref {
+		t.Errorf("recursive type should be referenced from definitions, but got %v", root)
+	}
+	node := schema["definitions"].(map[string]interface{})["configor_test.RecursiveNode"].(map[string]interface{})["properties"].(map[string]interface{})
+	if node["Next"].(map[string]interface{})["$ref"] != ref || node["Children"].(map[string]interface{})["items"].(map[string]interface{})["$ref"] != ref {
+		t.Errorf("recursive fields should reference the definition, but got %v", node)
+	}
+}
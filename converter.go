@@ -0,0 +1,102 @@
+package configor
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ipType  = reflect.TypeOf(net.IP{})
+	urlType = reflect.TypeOf(url.URL{})
+
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+)
+
+var converterRegistry = struct {
+	sync.RWMutex
+	converters map[reflect.Type]func(string) (reflect.Value, error)
+}{converters: map[reflect.Type]func(string) (reflect.Value, error){}}
+
+// RegisterConverter registers a converter to parse values from env and default tags into fields of typ, fields of pointers to typ
+// are also converted with it. Converters for time.Duration, time.Time, *time.Location, []byte, net.IP, url.URL, *big.Int and *big.Float are built in. e.g.
+//
+//	configor.RegisterConverter(reflect.TypeOf(big.Int{}), func(value string) (reflect.Value, error) {...})
+func RegisterConverter(typ reflect.Type, fn func(string) (reflect.Value, error)) {
+	converterRegistry.Lock()
+	defer converterRegistry.Unlock()
+	converterRegistry.converters[typ] = fn
+}
+
+func getConverter(typ reflect.Type) (func(string) (reflect.Value, error), bool) {
+	converterRegistry.RLock()
+	defer converterRegistry.RUnlock()
+	fn, ok := converterRegistry.converters[typ]
+	return fn, ok
+}
+
+func init() {
+	RegisterConverter(durationType, func(value string) (reflect.Value, error) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			// plain numbers are nanoseconds
+			if nanoseconds, parseErr := strconv.ParseInt(value, 10, 64); parseErr == nil {
+				duration, err = time.Duration(nanoseconds), nil
+			}
+		}
+		return reflect.ValueOf(duration), err
+	})
+
+	RegisterConverter(timeType, func(value string) (reflect.Value, error) {
+		t, err := parseTime(value, naiveTimeLayouts...)
+		return reflect.ValueOf(t), err
+	})
+
+	RegisterConverter(locationType, func(value string) (reflect.Value, error) {
+		location, err := time.LoadLocation(value)
+		return reflect.ValueOf(location), err
+	})
+
+	RegisterConverter(bytesType, func(value string) (reflect.Value, error) {
+		bytes, err := decodeBytes(value)
+		return reflect.ValueOf(bytes), err
+	})
+
+	RegisterConverter(ipType, func(value string) (reflect.Value, error) {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return reflect.Value{}, &net.ParseError{Type: "IP address", Text: value}
+		}
+		return reflect.ValueOf(ip), nil
+	})
+
+	RegisterConverter(urlType, func(value string) (reflect.Value, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(*u), nil
+	})
+
+	RegisterConverter(bigIntType, func(value string) (reflect.Value, error) {
+		n, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("invalid big integer %q", value)
+		}
+		return reflect.ValueOf(n), nil
+	})
+
+	RegisterConverter(bigFloatType, func(value string) (reflect.Value, error) {
+		f, ok := new(big.Float).SetString(value)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("invalid big float %q", value)
+		}
+		return reflect.ValueOf(f), nil
+	})
+}
@@ -0,0 +1,34 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// deprecate warns if the field tagged with `deprecated:"<message>"` is set from files or env, and copies its value to the sibling
+// field named by the `replaced_by` tag if that one is blank or set by its default tag
+func (configor *Configor) deprecate(result *LoadResult, field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, source ValueSource) error {
+	message, ok := fieldStruct.Tag.Lookup("deprecated")
+	if !ok || source == "" {
+		return nil
+	}
+	configor.warn(result, fmt.Errorf("%v is deprecated: %v", fieldPath, message))
+
+	name := fieldStruct.Tag.Get("replaced_by")
+	if name == "" {
+		return nil
+	}
+
+	replacement := parent.FieldByName(name)
+	replacementPath := strings.TrimSuffix(fieldPath, fieldStruct.Name) + name
+	if !replacement.IsValid() || !field.Type().AssignableTo(replacement.Type()) {
+		return configor.fail(result, &PhaseError{Phase: PhaseValidate, Field: fieldPath, Err: fmt.Errorf("replacement %v of deprecated %v is not found or not of its type", replacementPath, fieldPath)})
+	}
+
+	if isBlank(replacement) || result.Sources[replacementPath] == ValueFromDefault {
+		replacement.Set(field)
+		result.Sources[replacementPath] = source
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// FieldInfo is the metadata of a configuration field
+type FieldInfo struct {
+	// Path is the dot-separated path of the field, e.g. DB.Port, Contacts[0].Email
+	Path     string
+	Type     string
+	Default  string
+	Required bool
+	// Env is the env name of the field with the primary prefix
+	Env string
+	// Desc is the help text from the `desc` tag
+	Desc string
+}
+
+// Describe returns metadata of all fields of config, nested structs are walked into rather than described
+func Describe(config interface{}) []FieldInfo {
+	return New().describe(config)
+}
+
+func (configor *Configor) describe(config interface{}) []FieldInfo {
+	var results []FieldInfo
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if isNestedStruct(field.Type()) {
+			return nil
+		}
+
+		results = append(results, FieldInfo{
+			Path:     fieldPath,
+			Type:     field.Type().String(),
+			Default:  configor.getDefault(fieldStruct),
+			Required: isRequiredTag(fieldStruct),
+			Env:      configor.getEnvName(fieldStruct, fieldNames),
+			Desc:     fieldStruct.Tag.Get("desc"),
+		})
+		return nil
+	})
+	return results
+}
+
+// WithDescribeFormat set the format of Describe, could be markdown (default), plain or html
+func WithDescribeFormat(format string) Option {
+	return func(configor *Configor) {
+		configor.describeFormat = format
+	}
+}
+
+// Describe will write a table of all fields of the last loaded configuration, with their env names, types, current values,
+// defaults, required flags and validation rules, values of secret fields are masked
+func (configor *Configor) Describe(w io.Writer) error {
+	configor.state.mutex.RLock()
+	config := configor.state.config
+	configor.state.mutex.RUnlock()
+
+	if config == nil {
+		return errors.New("no configuration loaded")
+	}
+
+	header := []string{"Field", "Env", "Type", "Value", "Default", "Required", "Rules", "Description"}
+	var rows [][]string
+	err := walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if isNestedStruct(field.Type()) {
+			return nil
+		}
+
+		value := fmt.Sprint(field.Interface())
+		if configor.isSecret(fieldStruct) && !isBlank(field) {
+			value = "******"
+		}
+
+		var rules []string
+		for _, tag := range []string{"required_if", "oneof", "source", "min_duration", "max_duration"} {
+			if rule := fieldStruct.Tag.Get(tag); rule != "" {
+				rules = append(rules, tag+"="+rule)
+			}
+		}
+
+		var required string
+		if isRequiredTag(fieldStruct) {
+			required = "yes"
+		}
+
+		rows = append(rows, []string{
+			fieldPath, configor.getEnvName(fieldStruct, fieldNames), field.Type().String(), value,
+			configor.getDefault(fieldStruct), required, strings.Join(rules, ", "), fieldStruct.Tag.Get("desc"),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	switch configor.describeFormat {
+	case "", "markdown":
+		escape := strings.NewReplacer("|", "\\|", "\n", " ")
+		fmt.Fprintf(w, "| %v |\n", strings.Join(header, " | "))
+		fmt.Fprintf(w, "|%v\n", strings.Repeat(" --- |", len(header)))
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = escape.Replace(cell)
+			}
+			fmt.Fprintf(w, "| %v |\n", strings.Join(row, " | "))
+		}
+	case "plain":
+		writer := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	case "html":
+		fmt.Fprintf(w, "<table>\n  <tr><th>%v</th></tr>\n", strings.Join(header, "</th><th>"))
+		for _, row := range rows {
+			for i, cell := range row {
+				row[i] = html.EscapeString(cell)
+			}
+			fmt.Fprintf(w, "  <tr><td>%v</td></tr>\n", strings.Join(row, "</td><td>"))
+		}
+		fmt.Fprintln(w, "</table>")
+	default:
+		return fmt.Errorf("unknown describe format %v", configor.describeFormat)
+	}
+	return nil
+}
+
+// isSecret returns true if the field is tagged with `secret:"true"`, resolved by a SecretResolver or read from the `file` tag
+func (configor *Configor) isSecret(fieldStruct reflect.StructField) bool {
+	if fieldStruct.Tag.Get("secret") == "true" {
+		return true
+	}
+
+	for _, resolver := range configor.resolvers() {
+		if fieldStruct.Tag.Get(resolver.tag) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package configor
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// DumpEnv returns env names that would be read for fields of config, with current values of the fields formatted as env values
+func DumpEnv(config interface{}) map[string]string {
+	return New().DumpEnv(config)
+}
+
+// DumpEnv returns env names (for all prefixes) that would be read for fields of config, with current values of the fields
+// formatted as env values, it could be used to seed env for tests or generate a .env.example file
+func (configor *Configor) DumpEnv(config interface{}) map[string]string {
+	envs := map[string]string{}
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if isNestedStruct(field.Type()) {
+			return nil
+		}
+
+		value := formatValue(field)
+		for _, envName := range configor.getFieldEnvNames(fieldStruct, fieldNames) {
+			envs[envName] = value
+		}
+		return nil
+	})
+	return envs
+}
+
+// formatValue formats the field as a string which could be parsed back by setValue, nil pointers are formatted as blank
+func formatValue(field reflect.Value) string {
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return ""
+	}
+
+	if field.Type() == bytesType {
+		return "base64:" + base64.StdEncoding.EncodeToString(field.Bytes())
+	}
+
+	value := field.Interface()
+	if field.CanAddr() {
+		value = field.Addr().Interface()
+	}
+
+	switch v := value.(type) {
+	case encoding.TextMarshaler:
+		if text, err := v.MarshalText(); err == nil {
+			return string(text)
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	switch reflect.Indirect(field).Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		if js, err := json.Marshal(field.Interface()); err == nil {
+			return string(js)
+		}
+	}
+	return fmt.Sprint(reflect.Indirect(field).Interface())
+}
@@ -0,0 +1,108 @@
+package configor
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// EditFile will decode the YAML file into config, call edit to change it, then save it back to the file,
+// comments and key order of the file are preserved. Only the file is decoded, env and default tags are not applied
+func EditFile(file string, config interface{}, edit func(config interface{}) error) error {
+	if ext := strings.ToLower(fileFormat(file)); ext != ".yaml" && ext != ".yml" {
+		return errors.New("only yaml file could be edited")
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &document); err != nil {
+		return err
+	}
+
+	if err := document.Decode(config); err != nil && len(document.Content) > 0 {
+		return err
+	}
+
+	if err := edit(config); err != nil {
+		return err
+	}
+
+	var edited yamlv3.Node
+	if err := edited.Encode(config); err != nil {
+		return err
+	}
+
+	if len(document.Content) == 0 {
+		document = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{&edited}}
+	} else {
+		mergeNode(document.Content[0], &edited)
+	}
+
+	var buffer bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, buffer.Bytes(), fileInfo.Mode())
+}
+
+// mergeNode updates values of dst with src, while keeping comments and key order of dst, unknown keys of dst are kept
+func mergeNode(dst, src *yamlv3.Node) {
+	if dst.Kind != src.Kind {
+		dst.Kind, dst.Style, dst.Tag, dst.Value, dst.Content = src.Kind, src.Style, src.Tag, src.Value, src.Content
+		return
+	}
+
+	switch dst.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(src.Content); i += 2 {
+			key, value := src.Content[i], src.Content[i+1]
+
+			var found bool
+			for j := 0; j+1 < len(dst.Content); j += 2 {
+				if dst.Content[j].Value == key.Value {
+					mergeNode(dst.Content[j+1], value)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				dst.Content = append(dst.Content, key, value)
+			}
+		}
+	case yamlv3.SequenceNode:
+		for i, value := range src.Content {
+			if i < len(dst.Content) {
+				mergeNode(dst.Content[i], value)
+			} else {
+				dst.Content = append(dst.Content, value)
+			}
+		}
+
+		if len(dst.Content) > len(src.Content) {
+			dst.Content = dst.Content[:len(src.Content)]
+		}
+	default:
+		if dst.Value != src.Value || dst.Tag != src.Tag {
+			dst.Tag, dst.Value = src.Tag, src.Value
+			if src.Style != 0 {
+				dst.Style = src.Style
+			}
+		}
+	}
+}
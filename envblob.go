@@ -0,0 +1,54 @@
+package configor
+
+import (
+	"fmt"
+	"os"
+)
+
+// EnvSource is a whole configuration blob in an env, e.g. injected from a Kubernetes ConfigMap
+type EnvSource struct {
+	Name   string
+	Format string
+}
+
+// Read returns value of the env
+func (source EnvSource) Read() ([]byte, string, error) {
+	value, ok := os.LookupEnv(source.Name)
+	if !ok {
+		return nil, "", fmt.Errorf("env %v is not set", source.Name)
+	}
+	return []byte(value), source.Format, nil
+}
+
+// WithEnvJSON load the JSON configuration blob in the env if it's set, it has higher priority than files, and lower priority than env of fields
+func WithEnvJSON(name string) Option {
+	return func(configor *Configor) {
+		configor.envSources = append(configor.envSources, EnvSource{Name: name, Format: "json"})
+	}
+}
+
+// WithEnvYAML load the YAML configuration blob in the env if it's set, it has higher priority than files, and lower priority than env of fields
+func WithEnvYAML(name string) Option {
+	return func(configor *Configor) {
+		configor.envSources = append(configor.envSources, EnvSource{Name: name, Format: "yaml"})
+	}
+}
+
+// addEnvSources adds env sources which are set to sources with the highest priority, unless env is ignored
+func (configor *Configor) addEnvSources(sources []Source) []Source {
+	if configor.precedence == PrecedenceFilesOnly {
+		return sources
+	}
+
+	var matched []Source
+	for _, source := range configor.envSources {
+		if _, ok := configor.lookupEnvFunc(source.Name); ok {
+			matched = append(matched, source)
+		}
+	}
+
+	if configor.filePrecedence == LastFileWins {
+		return append(sources, matched...)
+	}
+	return append(matched, sources...)
+}
@@ -0,0 +1,90 @@
+package configor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// WithEnvFile read envs from a .env file when loading, they are only used by the Configor and won't be set to the process env,
+// envs in the file take precedence over the process env
+func WithEnvFile(file string) Option {
+	return func(configor *Configor) {
+		configor.envFile = file
+	}
+}
+
+// withEnvFile returns a copy of the Configor which looks up envs from the env file first
+func (configor *Configor) withEnvFile() (*Configor, error) {
+	if configor.envFile == "" {
+		return configor, nil
+	}
+
+	content, err := ioutil.ReadFile(configor.envFile)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := parseEnvFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse env file %v: %v", configor.envFile, err)
+	}
+
+	// envs of the file are looked up by the copy, so it's not read again
+	scoped := *configor
+	scoped.envFile = ""
+	scoped.lookupEnvFunc = func(name string) (string, bool) {
+		if value, ok := values[name]; ok {
+			return value, true
+		}
+		return configor.lookupEnvFunc(name)
+	}
+	scoped.environFunc = func() []string {
+		names := configor.environFunc()
+		for name := range values {
+			names = append(names, name)
+		}
+		return names
+	}
+	return &scoped, nil
+}
+
+// parseEnvFile parses lines like `KEY=value`, `export KEY="value"` or `# comment`
+func parseEnvFile(content []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" {
+			return nil, fmt.Errorf("invalid line %v: %v", lineNumber, line)
+		}
+
+		value := strings.TrimSpace(parts[1])
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value at line %v: %v", lineNumber, err)
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			// strip inline comments of unquoted values
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = strings.TrimSpace(value[:idx])
+			}
+		}
+		values[name] = value
+	}
+	return values, scanner.Err()
+}
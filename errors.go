@@ -0,0 +1,142 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConfigError is returned when failed to set a field's value from env or default tag
+type ConfigError struct {
+	// Field is the dot-separated path of the field, e.g. DB.Port, Contacts[0].Email
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid value %q for %v: %v", e.Value, e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// LoadPhase is the phase of loading configurations in which an error happened
+type LoadPhase string
+
+const (
+	// PhaseFile is resolving configuration files
+	PhaseFile LoadPhase = "file"
+	// PhaseDecode is decoding configuration files
+	PhaseDecode LoadPhase = "decode"
+	// PhaseEnv is reading fields from env
+	PhaseEnv LoadPhase = "env"
+	// PhaseResolve is resolving secrets with SecretResolvers
+	PhaseResolve LoadPhase = "resolve"
+	// PhaseDefault is setting `default` tags
+	PhaseDefault LoadPhase = "default"
+	// PhaseRequired is checking `required` tags
+	PhaseRequired LoadPhase = "required"
+	// PhaseValidate is validating the loaded configurations
+	PhaseValidate LoadPhase = "validate"
+	// PhaseFinalize is calling Finalize of Finalizers
+	PhaseFinalize LoadPhase = "finalize"
+)
+
+// PhaseError is an error collected with WithErrorCollection, with the phase and the file or field it happened on
+type PhaseError struct {
+	Phase LoadPhase
+	File  string
+	Field string
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("[%v] %v: %v", e.Phase, e.File, e.Err)
+	}
+	if e.Field != "" {
+		return fmt.Sprintf("[%v] %v: %v", e.Phase, e.Field, e.Err)
+	}
+	return fmt.Sprintf("[%v] %v", e.Phase, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+// LoadErrors are all errors collected in one load with WithErrorCollection
+type LoadErrors []error
+
+func (errs LoadErrors) Error() string {
+	var messages []string
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the collected errors, so errors.Is and errors.As could match any of them
+func (errs LoadErrors) Unwrap() []error {
+	return errs
+}
+
+// WithErrorCollection continue loading after errors when it's safe, and return all errors as LoadErrors at the end,
+// each error is a *PhaseError with the phase and the file or field it happened on
+func WithErrorCollection(collect bool) Option {
+	return func(configor *Configor) {
+		configor.collectErrors = collect
+	}
+}
+
+// fail returns the error to stop loading, or collects it into result to continue if collecting errors
+func (configor *Configor) fail(result *LoadResult, err *PhaseError) error {
+	if !configor.collectErrors {
+		return result.sanitize(err.Err)
+	}
+	result.errors = append(result.errors, result.sanitize(err))
+	return nil
+}
+
+// sanitizedError replaces secret values in the message of the underlying error with ****
+type sanitizedError struct {
+	err     error
+	secrets []string
+}
+
+func (e *sanitizedError) Error() string {
+	message := e.err.Error()
+	for _, secret := range e.secrets {
+		message = strings.ReplaceAll(message, secret, "****")
+	}
+	return message
+}
+
+// Unwrap returns the underlying error
+func (e *sanitizedError) Unwrap() error {
+	return e.err
+}
+
+// sanitize wraps err to scrub values of secret fields seen in this load from its message if it contains any of them
+func (result *LoadResult) sanitize(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	message := err.Error()
+	for _, secret := range result.secrets {
+		if strings.Contains(message, secret) {
+			return &sanitizedError{err: err, secrets: append([]string{}, result.secrets...)}
+		}
+	}
+	return err
+}
+
+// addSecret records the value of a secret field, so it will be scrubbed from errors
+func (result *LoadResult) addSecret(value string) {
+	if value != "" {
+		result.secrets = append(result.secrets, value)
+	}
+}
@@ -0,0 +1,4 @@
+package configor
+
+// ApplyLinkerVariables exports applyLinkerVariables for tests, as the build info of test binaries couldn't be set
+var ApplyLinkerVariables = applyLinkerVariables
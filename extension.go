@@ -0,0 +1,34 @@
+package configor
+
+import (
+	"path"
+	"strings"
+	"sync"
+)
+
+var extensionRegistry = struct {
+	sync.RWMutex
+	formats map[string]string
+}{formats: map[string]string{}}
+
+// RegisterExtension registers the format (yaml, toml or json) of files with the extension, consulted before the built-in
+// extensions when loading and saving files, e.g.
+//
+//	configor.RegisterExtension(".conf", "yaml")
+func RegisterExtension(ext, format string) {
+	extensionRegistry.Lock()
+	defer extensionRegistry.Unlock()
+	extensionRegistry.formats["."+strings.ToLower(strings.TrimPrefix(ext, "."))] = "." + strings.ToLower(strings.TrimPrefix(format, "."))
+}
+
+// fileFormat returns the format of the file from its extension, with registered extensions mapped to their formats
+func fileFormat(file string) string {
+	ext := path.Ext(file)
+
+	extensionRegistry.RLock()
+	defer extensionRegistry.RUnlock()
+	if format, ok := extensionRegistry.formats[strings.ToLower(ext)]; ok {
+		return format
+	}
+	return ext
+}
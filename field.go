@@ -0,0 +1,66 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LoadField will load the single field of config whose env name is envKey, without populating other fields
+func LoadField(config interface{}, envKey string, opts ...Option) error {
+	return New(opts...).LoadField(config, envKey)
+}
+
+// LoadField will load the single field of config whose env name (with any prefix, or the `env` tag) is envKey (case insensitive)
+// from env, then apply its `default`, `required` and `required_if` tags and validate it with `min_duration` and `max_duration` tags,
+// other fields are not touched. It's useful to read a field early in startup, e.g. the path of configuration files
+func (configor *Configor) LoadField(config interface{}, envKey string) error {
+	var found bool
+	err := walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if found || isNestedStruct(field.Type()) {
+			return nil
+		}
+
+		// the env is read by the name of the field, as env names are case sensitive
+		var matchedName string
+		for _, envName := range configor.getFieldEnvNames(fieldStruct, fieldNames) {
+			if matchedName == "" && strings.EqualFold(envName, envKey) {
+				matchedName = envName
+			}
+		}
+		if matchedName == "" {
+			return nil
+		}
+		found = true
+
+		if value, ok := configor.lookupEnv([]string{matchedName}, false); ok && value != Default {
+			if err := configor.parseValue(field, value); err != nil {
+				return &ConfigError{Field: fieldPath, Value: value, Err: err}
+			}
+		}
+
+		if isBlank(field) {
+			if value := configor.getDefault(fieldStruct); value != "" {
+				if err := configor.parseValue(field, value); err != nil {
+					return &ConfigError{Field: fieldPath, Value: value, Err: err}
+				}
+			} else if isRequiredTag(fieldStruct) {
+				return requiredError(fieldStruct, fieldPath)
+			}
+		}
+
+		if condition := fieldStruct.Tag.Get("required_if"); condition != "" && isBlank(field) {
+			if required, err := matchCondition(parent, condition); err != nil {
+				return fmt.Errorf("invalid required_if tag of %v: %v", fieldPath, err)
+			} else if required {
+				return fmt.Errorf("%v is required if %v, but blank", fieldPath, condition)
+			}
+		}
+		return checkDurationField(field, fieldStruct, fieldPath)
+	})
+
+	if err == nil && !found {
+		return fmt.Errorf("no field found for env %v", envKey)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// fileResolver resolves fields tagged with `file:"<path>"` from contents of the files, relative paths are resolved against the base dir
+type fileResolver struct {
+	configor *Configor
+}
+
+func (resolver fileResolver) Resolve(path string) (string, error) {
+	return readFileRef(resolver.configor.resolvePath(path))
+}
+
+// readFileRef returns the content of the referenced file with trailing newlines trimmed, like secrets mounted as files
+func readFileRef(file string) (string, error) {
+	content, err := ioutil.ReadFile(file)
+	return strings.TrimRight(string(content), "\r\n"), err
+}
+
+// resolvers returns registered secret resolvers, and the resolver of the `file` tag as the last one
+func (configor *Configor) resolvers() []secretResolver {
+	return append(append([]secretResolver{}, configor.secretResolvers...), secretResolver{tag: "file", resolver: fileResolver{configor: configor}})
+}
+
+// WithFileEnvSuffix read fields from files referenced by envs with the suffix if their envs are not set, e.g. the file at
+// CONFIGOR_DB_PASSWORD_FILE for DB.Password with suffix _FILE, like secrets mounted as files. It's disabled by default, as
+// the env name could be the env of another field, e.g. CONFIGOR_CONFIG_FILE of ConfigFile
+func WithFileEnvSuffix(suffix string) Option {
+	return func(configor *Configor) {
+		configor.fileEnvSuffix = suffix
+	}
+}
+
+// fileEnvNames returns names of envs referencing files for envNames, e.g. CONFIGOR_DB_PASSWORD_FILE for CONFIGOR_DB_PASSWORD,
+// blank if file envs are disabled
+func (configor *Configor) fileEnvNames(envNames []string) []string {
+	if configor.fileEnvSuffix == "" {
+		return nil
+	}
+
+	names := make([]string, len(envNames))
+	for i, envName := range envNames {
+		names[i] = envName + configor.fileEnvSuffix
+	}
+	return names
+}
+
+// fileRefs returns files referenced by the field, from the first file env that's set and the `file` tag
+func (configor *Configor) fileRefs(field reflect.Value, fieldStruct reflect.StructField, fieldNames []string) []string {
+	var files []string
+	if configor.shouldReadEnv(field) {
+		if file, ok := configor.lookupEnv(configor.fileEnvNames(configor.getFieldEnvNames(fieldStruct, fieldNames)), false); ok {
+			files = append(files, file)
+		}
+	}
+	if file := fieldStruct.Tag.Get("file"); file != "" {
+		files = append(files, configor.resolvePath(file))
+	}
+	return files
+}
+
+// WithFileCheck check files referenced by fields exist and are readable before applying env and tags when loading, see CheckFiles
+func WithFileCheck(check bool) Option {
+	return func(configor *Configor) {
+		configor.checkFiles = check
+	}
+}
+
+// CheckFiles checks files referenced by fields of config exist and are readable, returns an error listing all missing files
+func CheckFiles(config interface{}) error {
+	return New().CheckFiles(config)
+}
+
+// CheckFiles checks files referenced by fields of config, with file envs (see WithFileEnvSuffix) or `file` tags,
+// exist and are readable, returns an error listing all missing files, so missing secret mounts are caught before they're used
+func (configor *Configor) CheckFiles(config interface{}) error {
+	var missing []string
+	err := walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		for _, file := range configor.fileRefs(field, fieldStruct, fieldNames) {
+			if err := checkFile(file); err != nil {
+				missing = append(missing, fmt.Sprintf("%v: %v", fieldPath, err))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return errors.New("missing referenced files:\n" + strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+// checkFile returns an error if file doesn't exist, isn't readable or is a directory
+func checkFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%v is a directory", file)
+	}
+	return nil
+}
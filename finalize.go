@@ -0,0 +1,71 @@
+package configor
+
+import "reflect"
+
+// Finalizer is implemented by configs and their nested structs to derive computed fields after they're loaded, e.g. assemble a DSN
+// from host, port and user. Finalize is called as the last step of loading, after validation
+type Finalizer interface {
+	Finalize() error
+}
+
+// finalize calls Finalize of nested structs and then config, nested structs are finalized before the structs containing them
+func (configor *Configor) finalize(config interface{}, result *LoadResult) error {
+	return configor.finalizeValue(reflect.ValueOf(config), result)
+}
+
+// finalizeValue finalizes structs in value recursively like walkFields, including structs in slices and maps, elements of maps
+// are finalized on copies and set back
+func (configor *Configor) finalizeValue(value reflect.Value, result *LoadResult) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if !isNestedStruct(value.Type()) {
+			return nil
+		}
+		for i := 0; i < value.NumField(); i++ {
+			fieldStruct := value.Type().Field(i)
+			if fieldStruct.PkgPath != "" || fieldStruct.Tag.Get("configor") == "-" {
+				continue
+			}
+			if err := configor.finalizeValue(value.Field(i), result); err != nil {
+				return err
+			}
+		}
+
+		if value.CanAddr() {
+			if finalizer, ok := value.Addr().Interface().(Finalizer); ok {
+				if err := finalizer.Finalize(); err != nil {
+					return configor.fail(result, &PhaseError{Phase: PhaseFinalize, Err: err})
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if !isNestedStruct(value.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < value.Len(); i++ {
+			if err := configor.finalizeValue(value.Index(i), result); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !isNestedStruct(value.Type().Elem()) {
+			return nil
+		}
+		for _, key := range value.MapKeys() {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(value.MapIndex(key))
+			if err := configor.finalizeValue(elem, result); err != nil {
+				return err
+			}
+			value.SetMapIndex(key, elem)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithSecretRedaction mask values of secret fields (tagged with `secret:"true"` or resolved by a SecretResolver) with ****** in Flatten
+func WithSecretRedaction(redact bool) Option {
+	return func(configor *Configor) {
+		configor.redactSecrets = redact
+	}
+}
+
+// Flatten returns fields of config as `dotted.path` keys with their values formatted as strings, it's the inverse of ApplyOverrides
+func Flatten(config interface{}) map[string]string {
+	return New().Flatten(config)
+}
+
+// Flatten returns leaf fields of config keyed by their dot-separated paths, e.g. DB.Port, Contacts[0].Email, Labels[env], with
+// values formatted like env values. Elements of slices and maps are flattened with indices and keys in brackets
+func (configor *Configor) Flatten(config interface{}) map[string]string {
+	results := map[string]string{}
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		format := formatValue
+		if configor.redactSecrets && configor.isSecret(fieldStruct) {
+			format = func(value reflect.Value) string {
+				if isBlank(value) {
+					return ""
+				}
+				return "******"
+			}
+		}
+		flattenValue(results, field, fieldPath, format)
+		return nil
+	})
+	return results
+}
+
+// flattenValue adds the value to results, elements of slices and maps are added with indices and keys, structs in them are
+// skipped as their fields are walked by walkFields
+func flattenValue(results map[string]string, value reflect.Value, path string, format func(reflect.Value) string) {
+	typ := value.Type()
+	if isNestedStruct(typ) {
+		return
+	}
+
+	switch {
+	case (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ != bytesType && !hasConverter(typ):
+		if isNestedStruct(typ.Elem()) {
+			return
+		}
+		for i := 0; i < value.Len(); i++ {
+			flattenValue(results, value.Index(i), fmt.Sprintf("%v[%d]", path, i), format)
+		}
+	case typ.Kind() == reflect.Map:
+		if isNestedStruct(typ.Elem()) {
+			return
+		}
+		for _, key := range value.MapKeys() {
+			flattenValue(results, value.MapIndex(key), fmt.Sprintf("%v[%v]", path, key), format)
+		}
+	default:
+		results[path] = format(value)
+	}
+}
+
+func hasConverter(typ reflect.Type) bool {
+	_, ok := getConverter(typ)
+	return ok
+}
@@ -0,0 +1,41 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/configor"
+)
+
+func FuzzLoad(f *testing.F) {
+	f.Add([]byte("appname: test\ndb:\n  name: test\n  password: test\n  port: 1234\ncontacts:\n- name: test\n  email: test@test.com\n"), ".yml")
+	f.Add([]byte(`{"APPName": "test", "DB": {"Name": "test", "Password": "test", "Port": 1234}, "Contacts": [{"Email": "test@test.com"}]}`), ".json")
+	f.Add([]byte("APPName = \"test\"\n[DB]\nName = \"test\"\nPassword = \"test\"\nPort = 1234\n"), ".toml")
+	f.Add([]byte("APPName = \"test\"\n[DB]\nPassword = \"test\"\n"), "")
+	f.Add([]byte("db: [1, 2\n"), ".yaml")
+
+	f.Fuzz(func(t *testing.T, data []byte, ext string) {
+		switch ext {
+		case "", ".yml", ".yaml", ".json", ".toml":
+		default:
+			t.Skip()
+		}
+
+		file := filepath.Join(t.TempDir(), "config"+ext)
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("failed to write config file, got %v", err)
+		}
+
+		// errors are expected for malformed input, only panics are failures
+		var result Config
+		configor.Load(&result, file)
+
+		var anything struct {
+			Values  map[string]interface{}
+			List    []interface{}
+			Backend interface{}
+		}
+		configor.New(configor.WithKeyNormalization(true)).Load(&anything, file)
+	})
+}
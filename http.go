@@ -0,0 +1,122 @@
+package configor
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// WithHTTPAuth set the username and password for HTTP Basic Auth when loading configurations from URLs
+func WithHTTPAuth(username, password string) Option {
+	return func(configor *Configor) {
+		configor.httpHeader.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+}
+
+// WithHTTPBearerToken set the bearer token when loading configurations from URLs
+func WithHTTPBearerToken(token string) Option {
+	return func(configor *Configor) {
+		configor.httpHeader.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithHTTPHeader set a header when loading configurations from URLs
+func WithHTTPHeader(key, value string) Option {
+	return func(configor *Configor) {
+		configor.httpHeader.Set(key, value)
+	}
+}
+
+// WithHTTPClient set the client to load configurations from URLs, default is http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(configor *Configor) {
+		configor.httpClient = client
+	}
+}
+
+// WithHTTPTimeout set the timeout of loading configurations from URLs, including reading the response body, default is 30s,
+// 0 means no timeout
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return func(configor *Configor) {
+		configor.httpTimeout = timeout
+	}
+}
+
+// redactURL replaces the password in URLs with xxxxx for logs and errors, other names are returned as is
+func redactURL(name string) string {
+	if !isURL(name) {
+		return name
+	}
+	if u, err := url.Parse(name); err == nil {
+		return u.Redacted()
+	}
+	return "invalid URL"
+}
+
+func isURL(file string) bool {
+	return strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://")
+}
+
+// fetch retrieves configurations from the URL, returns its content and format detected from the URL's extension or content type,
+// auth material is never included in errors
+func (configor *Configor) fetch(rawURL string) ([]byte, string, error) {
+	requestURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid configuration URL")
+	}
+
+	ctx := context.Background()
+	if configor.httpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, configor.httpTimeout)
+		defer cancel()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid configuration URL %v", requestURL.Redacted())
+	}
+	for key, values := range configor.httpHeader {
+		request.Header[key] = values
+	}
+
+	client := configor.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	// errors returned from http.Client strip passwords from URLs
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch configuration %v: %v", requestURL.Redacted(), response.Status)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := fileFormat(requestURL.Path)
+	if mediaType, _, err := mime.ParseMediaType(response.Header.Get("Content-Type")); format == "" && err == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "json"):
+			format = "json"
+		case strings.HasSuffix(mediaType, "yaml"):
+			format = "yaml"
+		case strings.HasSuffix(mediaType, "toml"):
+			format = "toml"
+		}
+	}
+	return data, format, nil
+}
@@ -0,0 +1,82 @@
+package configor
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// resolveIncludes replaces nodes tagged with `!include path/to/other.yaml` in the YAML data with the content of the referenced file,
+// relative paths are resolved against the directory of file, circular includes are reported as errors
+func resolveIncludes(data []byte, file string, including []string) ([]byte, error) {
+	var document yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if err := resolveIncludeNode(&document, file, including); err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		return data, nil
+	}
+
+	var buffer bytes.Buffer
+	if err := yamlv3.NewEncoder(&buffer).Encode(&document); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func resolveIncludeNode(node *yamlv3.Node, file string, including []string) error {
+	if node.Tag == "!include" {
+		includeFile := node.Value
+		if !filepath.IsAbs(includeFile) {
+			includeFile = filepath.Join(filepath.Dir(file), includeFile)
+		}
+
+		absFile, err := filepath.Abs(includeFile)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range including {
+			if f == absFile {
+				return fmt.Errorf("circular include %v", strings.Join(append(including, absFile), " -> "))
+			}
+		}
+
+		data, err := ioutil.ReadFile(includeFile)
+		if err != nil {
+			return err
+		}
+
+		var included yamlv3.Node
+		if err := yamlv3.Unmarshal(data, &included); err != nil {
+			return err
+		}
+
+		if err := resolveIncludeNode(&included, includeFile, append(including, absFile)); err != nil {
+			return err
+		}
+
+		if len(included.Content) == 0 {
+			*node = yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}
+		} else {
+			*node = *included.Content[0]
+		}
+		return nil
+	}
+
+	for _, child := range node.Content {
+		if err := resolveIncludeNode(child, file, including); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithEnvInheritance set parents of environments, env files of the parents are loaded before the env file of current environment,
+// e.g. map[string]string{"staging": "production"} loads `config.production.yml` then `config.staging.yml` for staging
+func WithEnvInheritance(parents map[string]string) Option {
+	return func(configor *Configor) {
+		configor.envParents = parents
+	}
+}
+
+// envChain returns the environment with its ancestors, the farthest ancestor first, the chain is truncated with an error
+// if the inheritance is circular
+func (configor *Configor) envChain(env string) ([]string, error) {
+	chain := []string{env}
+	visited := map[string]bool{env: true}
+	for parent, ok := configor.envParents[env]; ok && parent != ""; parent, ok = configor.envParents[parent] {
+		if visited[parent] {
+			return chain, fmt.Errorf("circular inheritance of environments: %v -> %v", strings.Join(reversed(chain), " -> "), parent)
+		}
+		visited[parent] = true
+		chain = append([]string{parent}, chain...)
+	}
+	return chain, nil
+}
+
+func reversed(values []string) []string {
+	results := make([]string, len(values))
+	for i, value := range values {
+		results[len(values)-1-i] = value
+	}
+	return results
+}
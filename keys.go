@@ -0,0 +1,31 @@
+package configor
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Keys returns sorted dot-separated paths of all fields of the last loaded configuration, e.g. DB.Port, Contacts[0].Email,
+// nested structs are walked into rather than returned
+func (configor *Configor) Keys() []string {
+	configor.state.mutex.RLock()
+	config, keys := configor.state.config, configor.state.keys
+	if keys == nil && config != nil {
+		keys = []string{}
+		walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+			if !isNestedStruct(field.Type()) {
+				keys = append(keys, fieldPath)
+			}
+			return nil
+		})
+		sort.Strings(keys)
+	}
+	configor.state.mutex.RUnlock()
+
+	configor.state.mutex.Lock()
+	if configor.state.config == config {
+		configor.state.keys = keys
+	}
+	configor.state.mutex.Unlock()
+	return keys
+}
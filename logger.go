@@ -0,0 +1,38 @@
+package configor
+
+import "log"
+
+// Logger is where messages of configor are written to, *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// WithLogger set the logger for warnings and informational messages, default is the standard logger
+func WithLogger(logger Logger) Option {
+	return func(configor *Configor) {
+		configor.logger = logger
+	}
+}
+
+// WithVerbose output informational messages to the logger, e.g. which files are loaded and when example files are used
+// because configuration files are not found, they're silent by default
+func WithVerbose(verbose bool) Option {
+	return func(configor *Configor) {
+		configor.verbose = verbose
+	}
+}
+
+func (configor *Configor) warnf(format string, args ...interface{}) {
+	if configor.logger != nil {
+		configor.logger.Printf("[configor] "+format, args...)
+	} else {
+		log.Printf("[configor] "+format, args...)
+	}
+}
+
+// infof outputs the informational message only if verbose
+func (configor *Configor) infof(format string, args ...interface{}) {
+	if configor.verbose {
+		configor.warnf(format, args...)
+	}
+}
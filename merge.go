@@ -0,0 +1,58 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Merge merges non-zero fields of srcs onto dst deeply in order, so later sources have higher priority, srcs should be
+// structs (or pointers to structs) of dst's type. Slices are replaced as a whole, while maps are merged by keys
+func Merge(dst interface{}, srcs ...interface{}) error {
+	dstValue := reflect.ValueOf(dst)
+	if dstValue.Kind() != reflect.Ptr || dstValue.Elem().Kind() != reflect.Struct {
+		return errors.New("invalid dst, should be pointer to struct")
+	}
+
+	for _, src := range srcs {
+		srcValue := reflect.Indirect(reflect.ValueOf(src))
+		if !srcValue.IsValid() {
+			continue
+		}
+		if srcValue.Type() != dstValue.Elem().Type() {
+			return fmt.Errorf("invalid src %v, should be %v", srcValue.Type(), dstValue.Elem().Type())
+		}
+		mergeValue(dstValue.Elem(), srcValue)
+	}
+	return nil
+}
+
+// mergeValue sets src to dst if src is not blank, fields of nested structs and keys of maps are merged one by one
+func mergeValue(dst, src reflect.Value) {
+	if isBlank(src) {
+		return
+	}
+
+	switch {
+	case src.Kind() == reflect.Ptr && isNestedStruct(src.Type()):
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		mergeValue(dst.Elem(), src.Elem())
+	case src.Kind() == reflect.Struct && isNestedStruct(src.Type()):
+		for i := 0; i < src.NumField(); i++ {
+			if field := dst.Field(i); field.CanSet() {
+				mergeValue(field, src.Field(i))
+			}
+		}
+	case src.Kind() == reflect.Map:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMap(dst.Type()))
+		}
+		for _, key := range src.MapKeys() {
+			dst.SetMapIndex(key, src.MapIndex(key))
+		}
+	default:
+		dst.Set(src)
+	}
+}
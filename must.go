@@ -0,0 +1,32 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MustLoad will load configurations like Load, and panic if failed
+func MustLoad(config interface{}, files ...string) {
+	New().MustLoad(config, files...)
+}
+
+// MustLoad will load configurations like Load, and panic with a formatted message listing all errors if failed
+func (configor *Configor) MustLoad(config interface{}, files ...string) {
+	if err := configor.Load(config, files...); err != nil {
+		panic(formatLoadError(err, files))
+	}
+}
+
+func formatLoadError(err error, files []string) string {
+	var errs = []error{err}
+	if multiErr, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = multiErr.Unwrap()
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "configor: failed to load configurations from %v:", files)
+	for _, err := range errs {
+		fmt.Fprintf(&message, "\n  - %v", strings.Replace(err.Error(), "\n", "\n    ", -1))
+	}
+	return message.String()
+}
@@ -0,0 +1,62 @@
+package configor
+
+import "strings"
+
+// NamingConvention decides how env names are generated from paths of fields
+type NamingConvention int
+
+const (
+	// NamingScreamingSnake generates env names like `CONFIGOR_DATABASE_HOST`, it's the default
+	NamingScreamingSnake NamingConvention = iota
+	// NamingKebab generates env names like `CONFIGOR-DATABASE-HOST`
+	NamingKebab
+	// NamingCamel generates env names like `ConfigorDatabaseHost`
+	NamingCamel
+	// NamingLower generates env names like `configor_database_host`
+	NamingLower
+)
+
+// WithNamingConvention set the naming convention of env names, the `env` tag always overrides it
+func WithNamingConvention(convention NamingConvention) Option {
+	return func(configor *Configor) {
+		configor.namingConvention = convention
+	}
+}
+
+// WithEnvVarSeparator set the separator between the prefix and names of fields in env names, e.g. `__` for `CONFIGOR__DB__NAME`,
+// default is `_`, or `-` with NamingKebab
+func WithEnvVarSeparator(sep string) Option {
+	return func(configor *Configor) {
+		configor.envVarSeparator = sep
+	}
+}
+
+// formatEnvName joins names of the path (with prefix) by the naming convention
+func (configor *Configor) formatEnvName(names []string) string {
+	sep := configor.envVarSeparator
+	switch configor.namingConvention {
+	case NamingKebab:
+		if sep == "" {
+			sep = "-"
+		}
+		return strings.ToUpper(strings.Join(names, sep))
+	case NamingCamel:
+		var parts []string
+		for _, part := range names {
+			if part != "" {
+				parts = append(parts, strings.ToUpper(part[:1])+part[1:])
+			}
+		}
+		return strings.Join(parts, sep)
+	case NamingLower:
+		if sep == "" {
+			sep = "_"
+		}
+		return strings.ToLower(strings.Join(names, sep))
+	default:
+		if sep == "" {
+			sep = "_"
+		}
+		return strings.ToUpper(strings.Join(names, sep))
+	}
+}
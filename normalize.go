@@ -0,0 +1,182 @@
+package configor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithKeyNormalization match keys in configuration files to struct fields without dashes, underscores and case,
+// e.g. `max-connections` will be decoded into field `MaxConnections`
+func WithKeyNormalization(enable bool) Option {
+	return func(configor *Configor) {
+		configor.normalizeKeys = enable
+	}
+}
+
+var keyReplacer = strings.NewReplacer("-", "", "_", "")
+
+func normalizeKey(key string) string {
+	return strings.ToLower(keyReplacer.Replace(key))
+}
+
+// decodeWithNormalizedKeys decodes data into a generic value, renames its keys to json names of fields of config, then decodes
+// it into config through json. Keys are matched without dashes, underscores and case only if WithKeyNormalization is enabled
+func (configor *Configor) decodeWithNormalizedKeys(config interface{}, data []byte, format string) error {
+	var generic interface{}
+	if err := Decode(&generic, data, format); err != nil {
+		return err
+	}
+
+	normalized := configor.transformFileValues(renameKeys(generic, reflect.TypeOf(config), configor.normalizeKeys), reflect.TypeOf(config))
+	// TOML local datetimes are decoded in local time zone, while they're in UTC in other formats
+	if configor.timeLayout == "" && strings.EqualFold(strings.TrimPrefix(format, "."), "toml") {
+		normalized = transformValues(normalized, reflect.TypeOf(config), parseTimes(""))
+	}
+	// values decoded as strings but not decodable from JSON strings, e.g. durations, are converted with converters of their types
+	normalized = transformValues(normalized, reflect.TypeOf(config), convertStrings)
+	if err := prepareDiscriminatedTypes(reflect.ValueOf(config), normalized, ""); err != nil {
+		return err
+	}
+
+	js, err := json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, config)
+}
+
+// normalizeKeys renames keys of maps in data to the json names of the matched fields of typ, keys are matched without dashes,
+// underscores and case
+func normalizeKeys(data interface{}, typ reflect.Type) interface{} {
+	return renameKeys(data, typ, true)
+}
+
+// renameKeys renames keys of maps in data to the json names of the matched fields of typ, keys are matched like decoders match
+// them (see matchKey) unless normalize is true
+func renameKeys(data interface{}, typ reflect.Type, normalize bool) interface{} {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch values := data.(type) {
+	case map[interface{}]interface{}:
+		results := map[string]interface{}{}
+		for key, value := range values {
+			results[fmt.Sprint(key)] = value
+		}
+		return renameKeys(results, typ, normalize)
+	case map[string]interface{}:
+		results := map[string]interface{}{}
+		if typ != nil && typ.Kind() == reflect.Struct {
+			fields := fieldKeys(typ, normalize)
+			for key, value := range values {
+				if fieldStruct, ok := matchKey(fields, key, normalize); ok {
+					results[jsonName(fieldStruct)] = renameKeys(value, fieldStruct.Type, normalize)
+				} else {
+					results[key] = renameKeys(value, nil, normalize)
+				}
+			}
+			return results
+		}
+
+		var elemType reflect.Type
+		if typ != nil && typ.Kind() == reflect.Map {
+			elemType = typ.Elem()
+		}
+		for key, value := range values {
+			results[key] = renameKeys(value, elemType, normalize)
+		}
+		return results
+	case []interface{}:
+		var elemType reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elemType = typ.Elem()
+		}
+
+		results := make([]interface{}, len(values))
+		for i, value := range values {
+			results[i] = renameKeys(value, elemType, normalize)
+		}
+		return results
+	default:
+		return data
+	}
+}
+
+// fieldKeys returns fields of typ keyed by names in their json, yaml and toml tags and their names in lower case, names are
+// normalized with normalizeKey if normalize is true
+func fieldKeys(typ reflect.Type, normalize bool) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		fieldStruct := typ.Field(i)
+		if normalize {
+			fields[normalizeKey(fieldStruct.Name)] = fieldStruct
+		} else {
+			fields[strings.ToLower(fieldStruct.Name)] = fieldStruct
+		}
+		for _, tag := range []string{"json", "yaml", "toml"} {
+			if name := strings.Split(fieldStruct.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+				if normalize {
+					name = normalizeKey(name)
+				}
+				fields[name] = fieldStruct
+			}
+		}
+	}
+	return fields
+}
+
+// matchKey returns the field of key in fields returned by fieldKeys, keys match tag names as is and field names case-insensitively
+// like decoders, or without dashes, underscores and case if normalize is true
+func matchKey(fields map[string]reflect.StructField, key string, normalize bool) (reflect.StructField, bool) {
+	if normalize {
+		fieldStruct, ok := fields[normalizeKey(key)]
+		return fieldStruct, ok
+	}
+	if fieldStruct, ok := fields[key]; ok {
+		return fieldStruct, ok
+	}
+	fieldStruct, ok := fields[strings.ToLower(key)]
+	return fieldStruct, ok
+}
+
+// jsonName returns the name of the field in json
+func jsonName(fieldStruct reflect.StructField) string {
+	if name := strings.Split(fieldStruct.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return fieldStruct.Name
+}
+
+// convertStrings is a transformer converting string values of fields with registered converters (except strings and times, which
+// are decoded from JSON strings as is) to their JSON values, e.g. "5s" of time.Duration to 5000000000, values are kept if they
+// couldn't be converted or the converted values don't survive the JSON round trip
+func convertStrings(value interface{}, typ reflect.Type) interface{} {
+	str, ok := value.(string)
+	if !ok || typ.Kind() == reflect.String || typ == timeType {
+		return value
+	}
+
+	convert, ok := getConverter(typ)
+	if !ok {
+		if convert, ok = getConverter(reflect.PtrTo(typ)); !ok {
+			return value
+		}
+	}
+
+	converted, err := convert(str)
+	if err != nil {
+		return value
+	}
+	js, err := json.Marshal(converted.Interface())
+	if err != nil {
+		return value
+	}
+	decoded := reflect.New(converted.Type())
+	if json.Unmarshal(js, decoded.Interface()) != nil || !reflect.DeepEqual(decoded.Elem().Interface(), converted.Interface()) {
+		return value
+	}
+	return json.RawMessage(js)
+}
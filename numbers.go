@@ -0,0 +1,162 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// WithDigitSeparator strip the digit-grouping separator from values of numeric fields set in files, env and default tags,
+// e.g. WithDigitSeparator(",") to load `1,000,000`. Only values that are numbers after stripping are changed
+func WithDigitSeparator(separator string) Option {
+	return func(configor *Configor) {
+		configor.digitSeparator = separator
+	}
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// stripDigitSeparator returns the value without the separator if the field is numeric and the result is a number
+func (configor *Configor) stripDigitSeparator(field reflect.Value, value string) string {
+	if configor.digitSeparator == "" || !isNumericKind(field.Kind()) {
+		return value
+	}
+
+	if stripped := strings.Replace(value, configor.digitSeparator, "", -1); isNumber(stripped) {
+		return stripped
+	}
+	return value
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+// stripDigitSeparators returns a transformer converting string values of numeric fields to numbers without the separator,
+// integers are kept as int64 or uint64 so they're encoded without losing precision
+func stripDigitSeparators(separator string) func(value interface{}, typ reflect.Type) interface{} {
+	return func(value interface{}, typ reflect.Type) interface{} {
+		if str, ok := value.(string); ok && isNumericKind(typ.Kind()) {
+			stripped := strings.Replace(str, separator, "", -1)
+			if number, err := strconv.ParseInt(stripped, 10, 64); err == nil {
+				return number
+			}
+			if number, err := strconv.ParseUint(stripped, 10, 64); err == nil {
+				return number
+			}
+			if number, err := strconv.ParseFloat(stripped, 64); err == nil {
+				return number
+			}
+		}
+		return value
+	}
+}
+
+// transformFileValues strips digit separators and parses timestamps with the time layout in data decoded from a file
+func (configor *Configor) transformFileValues(data interface{}, typ reflect.Type) interface{} {
+	if configor.digitSeparator != "" {
+		data = transformValues(data, typ, stripDigitSeparators(configor.digitSeparator))
+	}
+	if configor.timeLayout != "" {
+		data = transformValues(data, typ, parseTimes(configor.timeLayout))
+	}
+	return data
+}
+
+// transformData applies transformFileValues to data and encodes the result in its format with keys as they are, so it's
+// decoded like other files, e.g. with custom unmarshalers of fields and without normalizing keys. Data of unknown formats
+// is encoded in json
+func (configor *Configor) transformData(config interface{}, data []byte, format string) ([]byte, string, error) {
+	generic, err := decodeNumbers(data, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	generic = configor.transformFileValues(generic, reflect.TypeOf(config))
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "yaml", "yml":
+		data, err = yaml.Marshal(generic)
+	case "toml":
+		var buffer bytes.Buffer
+		err = toml.NewEncoder(&buffer).Encode(generic)
+		data = buffer.Bytes()
+	default:
+		data, err = json.Marshal(generic)
+		format = "json"
+	}
+	return data, format, err
+}
+
+// transformValues calls fn with values in data and types of the fields they will be decoded into, and replaces them with
+// the results. Keys of data are matched to fields like decoders match them, so data could be decoded from files as is, or
+// with keys renamed by renameKeys
+func transformValues(data interface{}, typ reflect.Type, fn func(value interface{}, typ reflect.Type) interface{}) interface{} {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return data
+	}
+
+	switch values := data.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			if isNestedStruct(typ) {
+				fields := fieldKeys(typ, false)
+				for key, value := range values {
+					if fieldStruct, ok := matchKey(fields, key, false); ok {
+						values[key] = transformValues(value, fieldStruct.Type, fn)
+					}
+				}
+				return data
+			}
+		case reflect.Map:
+			for key, value := range values {
+				values[key] = transformValues(value, typ.Elem(), fn)
+			}
+			return data
+		}
+	case map[interface{}]interface{}:
+		// maps decoded from yaml
+		switch typ.Kind() {
+		case reflect.Struct:
+			if isNestedStruct(typ) {
+				fields := fieldKeys(typ, false)
+				for key, value := range values {
+					if fieldStruct, ok := matchKey(fields, fmt.Sprint(key), false); ok {
+						values[key] = transformValues(value, fieldStruct.Type, fn)
+					}
+				}
+				return data
+			}
+		case reflect.Map:
+			for key, value := range values {
+				values[key] = transformValues(value, typ.Elem(), fn)
+			}
+			return data
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, value := range values {
+				values[i] = transformValues(value, typ.Elem(), fn)
+			}
+			return data
+		}
+	}
+	return fn(data, typ)
+}
@@ -0,0 +1,32 @@
+package configor
+
+import "reflect"
+
+// onceValues returns copies of fields tagged with `once:"true"` that are set already, keyed by their paths
+func onceValues(config interface{}) map[string]interface{} {
+	values := map[string]interface{}{}
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if fieldStruct.Tag.Get("once") == "true" && !isBlank(field) {
+			if value, err := Clone(field.Interface()); err == nil {
+				values[fieldPath] = value
+			}
+		}
+		return nil
+	})
+	return values
+}
+
+// restoreOnceValues sets fields back to values returned by onceValues, so the first value set wins over later sources. Fields
+// are matched by paths when walking, so fields in maps are set back to the maps like other fields
+func restoreOnceValues(config interface{}, values map[string]interface{}) {
+	if len(values) == 0 {
+		return
+	}
+
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if value, ok := values[fieldPath]; ok && field.CanSet() {
+			field.Set(reflect.ValueOf(value))
+		}
+		return nil
+	})
+}
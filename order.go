@@ -0,0 +1,180 @@
+package configor
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// marshalerTypes are interfaces of custom marshalers, types implementing any of them are kept as is when ordering fields
+var marshalerTypes = []reflect.Type{
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*yaml.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*yamlv3.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*toml.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+}
+
+// isMarshaler returns true if the type or its pointer implements any custom marshaler
+func isMarshaler(typ reflect.Type) bool {
+	for _, marshalerType := range marshalerTypes {
+		if typ.Implements(marshalerType) || reflect.PtrTo(typ).Implements(marshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// orderFields returns a copy of config with fields of structs sorted by their `order` tags when saving, fields without the tag
+// are kept in their declaration order after ordered ones. config is returned as is if there are no `order` tags
+func orderFields(config interface{}) interface{} {
+	if config == nil {
+		return config
+	}
+
+	if ordered, changed := orderValue(reflect.ValueOf(config)); changed {
+		return ordered.Interface()
+	}
+	return config
+}
+
+// orderValue returns a copy of value with fields of structs sorted, including structs in slices and maps, returns false if
+// nothing is changed. Structs with custom marshalers are kept as is, as their methods couldn't be copied
+func orderValue(value reflect.Value) (reflect.Value, bool) {
+	if value.Kind() != reflect.Ptr && value.Kind() != reflect.Interface && isMarshaler(value.Type()) {
+		return value, false
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return value, false
+		}
+		return orderValue(value.Elem())
+	case reflect.Slice, reflect.Array:
+		return orderElems(value)
+	case reflect.Map:
+		return orderMapElems(value)
+	case reflect.Struct:
+	default:
+		return value, false
+	}
+
+	type orderedField struct {
+		order int
+		field reflect.StructField
+		value reflect.Value
+	}
+
+	var fields []orderedField
+	var changed bool
+	for i := 0; i < value.NumField(); i++ {
+		fieldStruct := value.Type().Field(i)
+		// structs with embedded fields are kept as is, as their methods couldn't be promoted to the copy
+		if fieldStruct.Anonymous {
+			return value, false
+		}
+
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		order := int(^uint(0) >> 1)
+		if tag := fieldStruct.Tag.Get("order"); tag != "" {
+			if n, err := strconv.Atoi(tag); err == nil {
+				order, changed = n, true
+			}
+		}
+
+		fieldValue, fieldChanged := orderValue(value.Field(i))
+		if fieldChanged {
+			fieldStruct.Type = fieldValue.Type()
+			changed = true
+		} else {
+			fieldValue = value.Field(i)
+		}
+		fields = append(fields, orderedField{order: order, field: fieldStruct, value: fieldValue})
+	}
+
+	if !changed {
+		return value, false
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].order < fields[j].order
+	})
+
+	var structFields []reflect.StructField
+	for _, field := range fields {
+		field.field.Index, field.field.Offset = nil, 0
+		structFields = append(structFields, field.field)
+	}
+
+	ordered := reflect.New(reflect.StructOf(structFields)).Elem()
+	for i, field := range fields {
+		ordered.Field(i).Set(field.value)
+	}
+	return ordered, true
+}
+
+// orderElems returns a slice of ordered elements of the slice or array, the element type is the type of ordered elements if
+// they're the same, otherwise interface{}
+func orderElems(value reflect.Value) (reflect.Value, bool) {
+	elems := make([]reflect.Value, value.Len())
+	var changed bool
+	for i := range elems {
+		var elemChanged bool
+		if elems[i], elemChanged = orderValue(value.Index(i)); !elemChanged {
+			elems[i] = value.Index(i)
+		}
+		changed = changed || elemChanged
+	}
+	if !changed {
+		return value, false
+	}
+
+	ordered := reflect.MakeSlice(reflect.SliceOf(commonType(elems)), len(elems), len(elems))
+	for i, elem := range elems {
+		ordered.Index(i).Set(elem)
+	}
+	return ordered, true
+}
+
+// orderMapElems returns a map of ordered values of the map like orderElems
+func orderMapElems(value reflect.Value) (reflect.Value, bool) {
+	keys := value.MapKeys()
+	elems := make([]reflect.Value, len(keys))
+	var changed bool
+	for i, key := range keys {
+		var elemChanged bool
+		if elems[i], elemChanged = orderValue(value.MapIndex(key)); !elemChanged {
+			elems[i] = value.MapIndex(key)
+		}
+		changed = changed || elemChanged
+	}
+	if !changed {
+		return value, false
+	}
+
+	ordered := reflect.MakeMapWithSize(reflect.MapOf(value.Type().Key(), commonType(elems)), len(keys))
+	for i, key := range keys {
+		ordered.SetMapIndex(key, elems[i])
+	}
+	return ordered, true
+}
+
+// commonType returns the type of values if they're the same, otherwise interface{}
+func commonType(values []reflect.Value) reflect.Type {
+	for _, value := range values[1:] {
+		if value.Type() != values[0].Type() {
+			return reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+	}
+	return values[0].Type()
+}
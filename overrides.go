@@ -0,0 +1,162 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ApplyOverrides will set fields of config from overrides in the form of `dotted.path=value`, e.g. `DB.Port=5432`, `Contacts[0].Email=a@b.c`,
+// field names are case insensitive, values are parsed like env and could be quoted, slices are grown if the index is out of range
+func ApplyOverrides(config interface{}, overrides []string) error {
+	return New().ApplyOverrides(config, overrides)
+}
+
+// ApplyOverrides will set fields of config from overrides like ApplyOverrides, slices are grown up to the max slice index
+// (see WithMaxSliceIndex), greater indices are rejected
+func (configor *Configor) ApplyOverrides(config interface{}, overrides []string) error {
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr || configValue.IsNil() {
+		return fmt.Errorf("invalid config, should be pointer")
+	}
+
+	for _, override := range overrides {
+		path, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return fmt.Errorf("invalid override %v, should be path=value", override)
+		}
+
+		path, value = strings.TrimSpace(path), unquote(strings.TrimSpace(value))
+		if err := setPath(configValue, parsePath(path), value, configor.maxSliceIndex); err != nil {
+			return &ConfigError{Field: path, Value: value, Err: err}
+		}
+	}
+	return nil
+}
+
+// parsePath splits a path like `Servers[0].Host` or `servers.0.host` into segments
+func parsePath(path string) []string {
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		for {
+			start := strings.Index(part, "[")
+			end := strings.Index(part, "]")
+			if start < 0 || end < start {
+				break
+			}
+
+			if start > 0 {
+				segments = append(segments, part[:start])
+			}
+			segments = append(segments, part[start+1:end])
+			part = part[end+1:]
+		}
+
+		if part != "" {
+			segments = append(segments, part)
+		}
+	}
+	return segments
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 {
+		switch {
+		case value[0] == '"' && value[len(value)-1] == '"':
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+		case value[0] == '\'' && value[len(value)-1] == '\'':
+			return value[1 : len(value)-1]
+		}
+	}
+	return value
+}
+
+// findField returns the field of the struct by name case insensitively, `json` and `yaml` tag names are also matched,
+// fields of embedded structs are promoted
+func findField(value reflect.Value, name string) reflect.Value {
+	for i := 0; i < value.NumField(); i++ {
+		fieldStruct := value.Type().Field(i)
+		if fieldStruct.Anonymous && fieldStruct.Type.Kind() == reflect.Struct {
+			if field := findField(value.Field(i), name); field.IsValid() {
+				return field
+			}
+		}
+
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		if strings.EqualFold(fieldStruct.Name, name) {
+			return value.Field(i)
+		}
+
+		for _, tag := range []string{"json", "yaml"} {
+			if tagName := strings.Split(fieldStruct.Tag.Get(tag), ",")[0]; tagName != "" && strings.EqualFold(tagName, name) {
+				return value.Field(i)
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// setPath navigates value by segments and sets the leaf with raw value, slices are grown up to maxIndex
+func setPath(value reflect.Value, segments []string, raw string, maxIndex int) error {
+	if len(segments) == 0 {
+		return setValue(value, raw)
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return setPath(value.Elem(), segments, raw, maxIndex)
+	case reflect.Struct:
+		field := findField(value, segments[0])
+		if !field.IsValid() {
+			return fmt.Errorf("field %v not found", segments[0])
+		}
+		return setPath(field, segments[1:], raw, maxIndex)
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(segments[0])
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index %v", segments[0])
+		}
+
+		if index >= value.Len() {
+			if value.Kind() == reflect.Array {
+				return fmt.Errorf("index %v out of range", index)
+			}
+			if index > maxIndex {
+				return fmt.Errorf("index %v exceeds the max slice index %d", index, maxIndex)
+			}
+			value.Set(reflect.AppendSlice(value, reflect.MakeSlice(value.Type(), index+1-value.Len(), index+1-value.Len())))
+		}
+		return setPath(value.Index(index), segments[1:], raw, maxIndex)
+	case reflect.Map:
+		key := reflect.New(value.Type().Key()).Elem()
+		if err := setValue(key, segments[0]); err != nil {
+			return fmt.Errorf("invalid key %v: %v", segments[0], err)
+		}
+
+		// map elements are not addressable, set a copy then put it back
+		elem := reflect.New(value.Type().Elem()).Elem()
+		if existing := value.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setPath(elem, segments[1:], raw, maxIndex); err != nil {
+			return err
+		}
+
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+		value.SetMapIndex(key, elem)
+		return nil
+	default:
+		return fmt.Errorf("could not set %v of %v", strings.Join(segments, "."), value.Type())
+	}
+}
@@ -0,0 +1,36 @@
+package configor
+
+import "reflect"
+
+// ConfigPrecedence decides the merge order of configuration files and env
+type ConfigPrecedence int
+
+const (
+	// PrecedenceFilesFirst loads files first, then overrides them with env, it's the default
+	PrecedenceFilesFirst ConfigPrecedence = iota
+	// PrecedenceEnvFirst loads env first, then overrides them with files, env is only used for fields blank in files
+	PrecedenceEnvFirst
+	// PrecedenceEnvOnly only loads env, files are ignored
+	PrecedenceEnvOnly
+	// PrecedenceFilesOnly only loads files, env is ignored
+	PrecedenceFilesOnly
+)
+
+// WithConfigPrecedence set the merge order of configuration files and env, default is PrecedenceFilesFirst
+func WithConfigPrecedence(precedence ConfigPrecedence) Option {
+	return func(configor *Configor) {
+		configor.precedence = precedence
+	}
+}
+
+// shouldReadEnv returns true if the field should be read from env under the precedence
+func (configor *Configor) shouldReadEnv(field reflect.Value) bool {
+	switch configor.precedence {
+	case PrecedenceFilesOnly:
+		return false
+	case PrecedenceEnvFirst:
+		return isBlank(field)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,111 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+)
+
+// Profile bundles files, env prefixes and required fields for a run mode of the application, e.g. server, worker or migrate
+type Profile struct {
+	// Files are loaded with lower priority than files passed to Load
+	Files []string
+	// Prefixes are env prefixes like WithPrefixes
+	Prefixes []string
+	// Required overrides `required` tags by dot-separated paths of fields, e.g. {"DB.Password": false, "Queue.URL": true},
+	// indexes of slices could be omitted to match all elements, e.g. Servers.Host
+	Required map[string]bool
+	// Options are applied to the Configor when loading with the profile
+	Options []Option
+}
+
+var profileRegistry = struct {
+	sync.RWMutex
+	profiles map[string]Profile
+}{profiles: map[string]Profile{}}
+
+// RegisterProfile registers a profile which could be used with UseProfile or WithProfile
+func RegisterProfile(name string, profile Profile) {
+	profileRegistry.Lock()
+	defer profileRegistry.Unlock()
+	profileRegistry.profiles[name] = profile
+}
+
+// UseProfile initialize a Configor loading with the registered profile, e.g. configor.UseProfile("worker").Load(&Config)
+func UseProfile(name string, opts ...Option) *Configor {
+	return New(append([]Option{WithProfile(name)}, opts...)...)
+}
+
+// WithProfile load with the registered profile
+func WithProfile(name string) Option {
+	return func(configor *Configor) {
+		configor.profile = name
+	}
+}
+
+// withProfile returns a copy of the Configor with the profile applied, and sources to load with profile files
+func (configor *Configor) withProfile(sources []Source) (*Configor, []Source, error) {
+	if configor.profile == "" {
+		return configor, sources, nil
+	}
+
+	profileRegistry.RLock()
+	profile, ok := profileRegistry.profiles[configor.profile]
+	profileRegistry.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("profile %v is not registered", configor.profile)
+	}
+
+	scoped := *configor
+	if len(profile.Prefixes) > 0 {
+		scoped.prefixes = profile.Prefixes
+	}
+	if profile.Required != nil {
+		scoped.requiredFields = map[string]bool{}
+		for _, requiredFields := range []map[string]bool{configor.requiredFields, profile.Required} {
+			for fieldPath, required := range requiredFields {
+				scoped.requiredFields[fieldPath] = required
+			}
+		}
+	}
+	for _, opt := range profile.Options {
+		opt(&scoped)
+	}
+
+	// earlier files have higher priority by default
+	if scoped.filePrecedence == LastFileWins {
+		sources = append(fileSources(profile.Files), sources...)
+	} else {
+		sources = append(append([]Source{}, sources...), fileSources(profile.Files)...)
+	}
+	return &scoped, sources, nil
+}
+
+var indexRegexp = regexp.MustCompile(`\[[^\]]*\]`)
+
+// isRequired returns true if the field is required by the `required` tag, or overridden by the profile
+func (configor *Configor) isRequired(fieldStruct reflect.StructField, fieldPath string) bool {
+	if required, ok := configor.requiredFields[fieldPath]; ok {
+		return required
+	}
+	if required, ok := configor.requiredFields[indexRegexp.ReplaceAllString(fieldPath, "")]; ok {
+		return required
+	}
+	return isRequiredTag(fieldStruct)
+}
+
+// isRequiredTag returns true if the field is tagged with `required:"true"` or `required:"<custom error message>"`
+func isRequiredTag(fieldStruct reflect.StructField) bool {
+	value := fieldStruct.Tag.Get("required")
+	return value != "" && value != "false"
+}
+
+// requiredError returns the error of the blank required field, the `required` tag is the message if it's not "true"
+func requiredError(fieldStruct reflect.StructField, fieldPath string) error {
+	if message := fieldStruct.Tag.Get("required"); message != "" && message != "true" && message != "false" {
+		return errors.New(message)
+	}
+	return errors.New(fieldPath + " is required, but blank")
+}
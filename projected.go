@@ -0,0 +1,50 @@
+package configor
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// LoadProjected will load configurations from a directory of key files, like a Kubernetes ConfigMap or Secret mounted as volume
+func LoadProjected(config interface{}, dir string) error {
+	return New().LoadProjected(config, dir)
+}
+
+// LoadProjected will load configurations from a directory of key files, like a Kubernetes ConfigMap or Secret mounted as volume,
+// each file name is used as the env name (case insensitive) of a field and its content (trailing newlines trimmed) is the value,
+// then default and required tags are applied like Load. Shell env is not used
+func (configor *Configor) LoadProjected(config interface{}, dir string) error {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{}
+	for _, fileInfo := range fileInfos {
+		// skip directories and Kubernetes' internal entries like ..data
+		if fileInfo.IsDir() || strings.HasPrefix(fileInfo.Name(), ".") {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(dir, fileInfo.Name()))
+		if err != nil {
+			return err
+		}
+		values[strings.ToUpper(fileInfo.Name())] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	projected := *configor
+	projected.lookupEnvFunc = func(name string) (string, bool) {
+		value, ok := values[strings.ToUpper(name)]
+		return value, ok
+	}
+	projected.environFunc = func() []string {
+		var names []string
+		for name := range values {
+			names = append(names, name)
+		}
+		return names
+	}
+	return projected.Load(config)
+}
@@ -0,0 +1,141 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithRangeCheck check numbers in files against types of the fields they will be decoded into, returns an error if a number
+// overflows its field, e.g. 70000 for an int16 field or -1 for an uint field, rather than relying on decoders which may truncate it
+func WithRangeCheck(check bool) Option {
+	return func(configor *Configor) {
+		configor.rangeCheck = check
+	}
+}
+
+// checkRanges returns a ConfigError for the first number in data that overflows the field it will be decoded into, data is
+// normalized by normalizeKeys
+func checkRanges(data interface{}, typ reflect.Type, fieldPath string) error {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil
+	}
+
+	switch values := data.(type) {
+	case map[string]interface{}:
+		switch typ.Kind() {
+		case reflect.Struct:
+			for i := 0; i < typ.NumField(); i++ {
+				fieldStruct := typ.Field(i)
+				name := fieldStruct.Name
+				if jsonName := strings.Split(fieldStruct.Tag.Get("json"), ",")[0]; jsonName != "" && jsonName != "-" {
+					name = jsonName
+				}
+				if value, ok := values[name]; ok {
+					if err := checkRanges(value, fieldStruct.Type, joinPath(fieldPath, fieldStruct.Name)); err != nil {
+						return err
+					}
+				}
+			}
+		case reflect.Map:
+			for key, value := range values {
+				if err := checkRanges(value, typ.Elem(), fmt.Sprintf("%v[%v]", fieldPath, key)); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+			for i, value := range values {
+				if err := checkRanges(value, typ.Elem(), fmt.Sprintf("%v[%d]", fieldPath, i)); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		if isNumericKind(typ.Kind()) && overflows(data, typ) {
+			return &ConfigError{Field: fieldPath, Value: fmt.Sprint(data), Err: fmt.Errorf("overflows %v", typ)}
+		}
+	}
+	return nil
+}
+
+// overflows returns true if the number can't be represented by typ, values that aren't numbers are left to decoders
+func overflows(value interface{}, typ reflect.Type) bool {
+	switch value := value.(type) {
+	case int:
+		return intOverflows(int64(value), typ)
+	case int64:
+		return intOverflows(value, typ)
+	case uint64:
+		return uintOverflows(value, typ)
+	case float64:
+		return floatOverflows(value, typ)
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			return intOverflows(n, typ)
+		}
+		if n, err := strconv.ParseUint(string(value), 10, 64); err == nil {
+			return uintOverflows(n, typ)
+		}
+		if f, err := value.Float64(); err == nil {
+			return floatOverflows(f, typ)
+		}
+		return true
+	}
+	return false
+}
+
+func intOverflows(n int64, typ reflect.Type) bool {
+	switch zero := reflect.Zero(typ); {
+	case zero.CanInt():
+		return zero.OverflowInt(n)
+	case zero.CanUint():
+		return n < 0 || zero.OverflowUint(uint64(n))
+	default:
+		return zero.OverflowFloat(float64(n))
+	}
+}
+
+func uintOverflows(n uint64, typ reflect.Type) bool {
+	switch zero := reflect.Zero(typ); {
+	case zero.CanInt():
+		return n > math.MaxInt64 || zero.OverflowInt(int64(n))
+	case zero.CanUint():
+		return zero.OverflowUint(n)
+	default:
+		return zero.OverflowFloat(float64(n))
+	}
+}
+
+func floatOverflows(f float64, typ reflect.Type) bool {
+	switch zero := reflect.Zero(typ); {
+	case zero.CanInt():
+		return f < math.MinInt64 || f >= math.MaxInt64 || zero.OverflowInt(int64(f))
+	case zero.CanUint():
+		return f < 0 || f >= math.MaxUint64 || zero.OverflowUint(uint64(f))
+	default:
+		return zero.OverflowFloat(f)
+	}
+}
+
+// decodeNumbers decodes data into a generic value like Decode, with json numbers kept as json.Number to check them precisely
+func decodeNumbers(data []byte, format string) (interface{}, error) {
+	var generic interface{}
+	var err error
+	if strings.EqualFold(strings.TrimPrefix(format, "."), "json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&generic)
+	} else {
+		err = Decode(&generic, data, format)
+	}
+	return generic, err
+}
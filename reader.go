@@ -0,0 +1,29 @@
+package configor
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// NewReader returns a reader streaming config serialized in the format (yaml, yml, toml or json) like SaveBytes, close it when done
+func NewReader(config interface{}, format string) (io.ReadCloser, error) {
+	return New().NewReader(config, format)
+}
+
+// NewReader returns a reader streaming config serialized in the format (yaml, yml, toml or json) like SaveBytes, config is
+// serialized in another goroutine while reading, so it shouldn't be changed until the reader returns io.EOF or an error.
+// Close it when done, closing it before the end stops serializing and releases the goroutine
+func (configor *Configor) NewReader(config interface{}, format string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "yaml", "yml", "toml", "json":
+	default:
+		return nil, errors.New("Unknown file type")
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(configor.encode(writer, config, format))
+	}()
+	return reader, nil
+}
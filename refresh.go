@@ -0,0 +1,54 @@
+package configor
+
+import (
+	"errors"
+	"reflect"
+)
+
+// RefreshEnv will re-apply env to config loaded before, without reading files or applying defaults
+func RefreshEnv(config interface{}) error {
+	return New().RefreshEnv(config)
+}
+
+// RefreshEnv will re-apply env (including the env file) to config loaded before, without reading files, applying defaults or
+// validating, fields whose env is unset keep their values. It's useful to pick up envs changed at runtime, e.g. injected by a sidecar.
+// Env is applied to a clone of config, which replaces config only if all envs are parsed successfully. The replacement is
+// synchronized with Describe, Keys and Unmarshal of the Configor, but not with other goroutines reading config directly,
+// synchronize them like onChange of Watch
+func (configor *Configor) RefreshEnv(config interface{}) error {
+	if configValue := reflect.ValueOf(config); configValue.Kind() != reflect.Ptr || configValue.IsNil() {
+		return errors.New("invalid config, should be pointer")
+	}
+
+	configor, err := configor.withEnvFile()
+	if err != nil {
+		return err
+	}
+
+	configValue := reflect.Indirect(reflect.ValueOf(config))
+	clone, err := Clone(configValue.Interface())
+	if err != nil {
+		return err
+	}
+
+	refreshed := reflect.New(configValue.Type())
+	refreshed.Elem().Set(reflect.ValueOf(clone))
+
+	result := &LoadResult{Sources: map[string]ValueSource{}, Warnings: &Warnings{}}
+	err = walkFields(refreshed.Interface(), "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		_, err := configor.applyEnv(result, field, fieldStruct, fieldPath, fieldNames, configor.isSecret(fieldStruct))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(result.errors) > 0 {
+		return result.errors
+	}
+
+	configor.state.mutex.Lock()
+	configValue.Set(refreshed.Elem())
+	configor.state.keys = nil
+	configor.state.mutex.Unlock()
+	return nil
+}
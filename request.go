@@ -0,0 +1,89 @@
+package configor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"reflect"
+)
+
+// LoadFromRequest will decode the named request header as a base64 encoded JSON or YAML overlay, and merge it over config,
+// nothing changed if the header is not set. The overlay is merged over a copy of config, which replaces config only if decoded
+// successfully. Only use it in trusted environments, e.g. for testing or behind an API gateway
+func LoadFromRequest(config interface{}, r *http.Request, header string) error {
+	value := r.Header.Get(header)
+	if value == "" {
+		return nil
+	}
+
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr || configValue.IsNil() {
+		return errors.New("invalid config, should be pointer")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		if data, err = base64.URLEncoding.DecodeString(value); err != nil {
+			return err
+		}
+	}
+
+	// JSON objects are decoded as JSON to match fields case insensitively, others are YAML
+	format := "yaml"
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		format = "json"
+	}
+
+	copied, err := Clone(configValue.Elem().Interface())
+	if err != nil {
+		return err
+	}
+	result := reflect.New(configValue.Elem().Type())
+	result.Elem().Set(reflect.ValueOf(copied))
+	if err := Decode(result.Interface(), data, format); err != nil {
+		return err
+	}
+
+	configValue.Elem().Set(result.Elem())
+	return nil
+}
+
+type requestConfigKey struct{}
+
+// OverrideMiddleware returns a middleware that merges the overlay from the named request header over a copy of base for each request,
+// the copy could be retrieved with FromContext(r.Context()), base is used if the header is not set. Invalid overlays are rejected
+// with 400 Bad Request
+func OverrideMiddleware(base interface{}, header string) func(http.Handler) http.Handler {
+	return New().OverrideMiddleware(base, header)
+}
+
+// OverrideMiddleware returns a middleware like OverrideMiddleware, invalid overlays are rejected with a generic message, as the
+// error may contain values of fields, the error is written to the logger of the Configor instead
+func (configor *Configor) OverrideMiddleware(base interface{}, header string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			config := base
+			if r.Header.Get(header) != "" {
+				cloned, err := Clone(base)
+				if err == nil {
+					err = LoadFromRequest(cloned, r, header)
+				}
+				if err != nil {
+					configor.warnf("invalid configuration override from %v: %v", header, err)
+					http.Error(w, "invalid configuration override", http.StatusBadRequest)
+					return
+				}
+				config = cloned
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestConfigKey{}, config)))
+		})
+	}
+}
+
+// FromContext returns the configuration stored by OverrideMiddleware, returns nil if not found
+func FromContext(ctx context.Context) interface{} {
+	return ctx.Value(requestConfigKey{})
+}
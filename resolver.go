@@ -0,0 +1,19 @@
+package configor
+
+// SecretResolver resolves secrets referenced in struct tags, e.g. `vault:"secret/data/myapp#password"`
+type SecretResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+type secretResolver struct {
+	tag      string
+	resolver SecretResolver
+}
+
+// WithSecretResolver registers a resolver for fields tagged with `<tag>:"<ref>"`, the resolver will be called with the ref
+// to fill the field if it's blank after files and env are loaded, before the `default` tag is applied
+func WithSecretResolver(tag string, resolver SecretResolver) Option {
+	return func(configor *Configor) {
+		configor.secretResolvers = append(configor.secretResolvers, secretResolver{tag: tag, resolver: resolver})
+	}
+}
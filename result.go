@@ -0,0 +1,97 @@
+package configor
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ValueSource is where the value of a field comes from
+type ValueSource string
+
+const (
+	// ValueFromFile means the value is set in configuration files (or the config passed in)
+	ValueFromFile ValueSource = "file"
+	// ValueFromEnv means the value is set from env
+	ValueFromEnv ValueSource = "env"
+	// ValueFromResolver means the value is resolved by a SecretResolver
+	ValueFromResolver ValueSource = "resolver"
+	// ValueFromDefault means the value is set from the `default` tag
+	ValueFromDefault ValueSource = "default"
+)
+
+// LoadResult is the result of LoadWithResult
+type LoadResult struct {
+	// Sources are where values of fields come from, keyed by the dot-separated path of fields, blank fields are not included
+	Sources map[string]ValueSource
+	// RedundantDefaults are paths of fields whose value set in files equals their `default` tag, they could be removed from files
+	RedundantDefaults []string
+	// Warnings are non-fatal errors skipped when loading configurations
+	Warnings *Warnings
+	// File is the loaded local file with the highest priority, and Format is its format, e.g. yaml, toml or json
+	File   string
+	Format string
+	// FormatVersions are format versions of files checked with WithMinVersion
+	FormatVersions []FormatVersion
+
+	config   interface{}
+	configor *Configor
+	errors   LoadErrors
+	// secrets are values of fields tagged with `secret:"true"`, scrubbed from errors
+	secrets []string
+}
+
+// SaveBack will save the loaded config to File in its original Format. Values from env, defaults and resolvers (e.g. secrets
+// injected by env) are not saved, fields set from them keep their values in File
+func (result *LoadResult) SaveBack() error {
+	if result.File == "" || result.config == nil {
+		return errors.New("no configuration file loaded")
+	}
+
+	configValue := reflect.Indirect(reflect.ValueOf(result.config))
+	clone, err := Clone(configValue.Interface())
+	if err != nil {
+		return err
+	}
+	saved := reflect.New(configValue.Type())
+	saved.Elem().Set(reflect.ValueOf(clone))
+
+	data, format, err := result.configor.readFile(result.File)
+	if err != nil {
+		return err
+	}
+	original := reflect.New(configValue.Type())
+	if err := Decode(original.Interface(), data, format); err != nil {
+		return err
+	}
+
+	err = walkFields(saved.Interface(), "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if source, ok := result.Sources[fieldPath]; !ok || source == ValueFromFile || !field.CanSet() {
+			return nil
+		}
+
+		if value, err := Field(original.Interface(), fieldPath); err == nil {
+			field.Set(value)
+		} else {
+			field.Set(reflect.Zero(field.Type()))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return result.configor.Save(saved.Interface(), result.File)
+}
+
+// Warnings are non-fatal errors skipped when loading configurations, e.g. missing files with graceful degradation
+type Warnings struct {
+	Errors []error
+}
+
+func (warnings *Warnings) String() string {
+	var messages []string
+	for _, err := range warnings.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
@@ -0,0 +1,239 @@
+package configor
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// JSONSchema generates a JSON Schema (draft-07) from the config struct, with types, required fields (from the `required` tag),
+// defaults (from the `default` tag), enums (from the `oneof` tag, separated by space) and descriptions (from the `desc` tag).
+// Recursive types are referenced with `$ref`, fields tagged with `configor:"-"` are skipped
+func JSONSchema(config interface{}) ([]byte, error) {
+	typ := reflect.TypeOf(config)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil, errors.New("invalid config, should not be nil")
+	}
+
+	builder := &schemaBuilder{root: typ, visiting: map[reflect.Type]bool{}, recursive: map[reflect.Type]bool{}, definitions: map[string]interface{}{}}
+	schema := builder.typeSchema(typ)
+	if len(builder.definitions) > 0 {
+		schema["definitions"] = builder.definitions
+	}
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaBuilder generates schemas of types, visiting holds struct types being generated to stop recursion, recursive types
+// (except the root type, referenced as `#`) are moved to definitions and referenced with `$ref`
+type schemaBuilder struct {
+	root        reflect.Type
+	visiting    map[reflect.Type]bool
+	recursive   map[reflect.Type]bool
+	definitions map[string]interface{}
+}
+
+func typeSchema(typ reflect.Type) map[string]interface{} {
+	return (&schemaBuilder{visiting: map[reflect.Type]bool{}, recursive: map[reflect.Type]bool{}, definitions: map[string]interface{}{}}).typeSchema(typ)
+}
+
+// ref returns the schema referencing the recursive type
+func (builder *schemaBuilder) ref(typ reflect.Type) map[string]interface{} {
+	if typ == builder.root {
+		return map[string]interface{}{"$ref": "#"}
+	}
+	return map[string]interface{}{"$ref": "#/definitions/" + typ.String()}
+}
+
+func (builder *schemaBuilder) typeSchema(typ reflect.Type) map[string]interface{} {
+	for typ.Kind() == reflect.Ptr && typ != locationType {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == durationType || typ == locationType || typ == bytesType || typ == ipType || typ == urlType:
+		return map[string]interface{}{"type": "string"}
+	case typ == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.PtrTo(typ).Implements(textUnmarshalerType):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": builder.typeSchema(typ.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": builder.typeSchema(typ.Elem())}
+	case reflect.Struct:
+		if builder.visiting[typ] {
+			builder.recursive[typ] = true
+			return builder.ref(typ)
+		}
+		builder.visiting[typ] = true
+		defer delete(builder.visiting, typ)
+
+		properties := map[string]interface{}{}
+		var required []string
+		for i := 0; i < typ.NumField(); i++ {
+			fieldStruct := typ.Field(i)
+			if fieldStruct.PkgPath != "" || fieldStruct.Tag.Get("configor") == "-" {
+				continue
+			}
+
+			name := fieldStruct.Name
+			if jsonName := strings.Split(fieldStruct.Tag.Get("json"), ",")[0]; jsonName == "-" {
+				continue
+			} else if jsonName != "" {
+				name = jsonName
+			}
+
+			property := builder.typeSchema(fieldStruct.Type)
+			if value := fieldStruct.Tag.Get("default"); value != "" {
+				property["default"] = schemaValue(fieldStruct.Type, value)
+			}
+			if values := fieldStruct.Tag.Get("oneof"); values != "" {
+				var enum []interface{}
+				for _, value := range strings.Fields(values) {
+					enum = append(enum, schemaValue(fieldStruct.Type, value))
+				}
+				property["enum"] = enum
+			}
+			if desc := fieldStruct.Tag.Get("desc"); desc != "" {
+				property["description"] = desc
+			}
+			if isRequiredTag(fieldStruct) {
+				required = append(required, name)
+			}
+			properties[name] = property
+		}
+
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		if builder.recursive[typ] && typ != builder.root {
+			builder.definitions[typ.String()] = schema
+			return builder.ref(typ)
+		}
+		return schema
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaValue parses value from tags into the type for the schema, the raw value is used for types written as string
+func schemaValue(typ reflect.Type, value string) interface{} {
+	if schemaType := typeSchema(typ)["type"]; schemaType == "string" {
+		return value
+	}
+
+	result := reflect.New(typ).Elem()
+	if setValue(result, value) != nil {
+		return value
+	}
+	return result.Interface()
+}
+
+// Schema is the structure of a config struct, it could be serialized to JSON or YAML for tools
+type Schema struct {
+	Fields []SchemaField `json:"fields" yaml:"fields"`
+}
+
+// SchemaField is a field of Schema, Children are fields of nested structs, including structs in slices and maps whose index or
+// key is `*` in Path and EnvVar, e.g. Contacts[*].Email, CONFIGOR_CONTACTS_*_EMAIL
+type SchemaField struct {
+	// Path is the dot-separated path of the field, e.g. DB.Port
+	Path string `json:"path" yaml:"path"`
+	// EnvVar is the env name of the field with the primary prefix, blank if it couldn't be set from env
+	EnvVar   string        `json:"envVar,omitempty" yaml:"envVar,omitempty"`
+	Type     string        `json:"type" yaml:"type"`
+	Default  string        `json:"default,omitempty" yaml:"default,omitempty"`
+	Required bool          `json:"required,omitempty" yaml:"required,omitempty"`
+	Children []SchemaField `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// InspectSchema returns the structure of config from its type, with env names, defaults and required flags of fields, without loading any files
+func InspectSchema(config interface{}) (Schema, error) {
+	return New().InspectSchema(config)
+}
+
+// InspectSchema returns the structure of config from its type, with env names, defaults and required flags of fields, without loading
+// any files. Fields of recursive types are inspected once
+func (configor *Configor) InspectSchema(config interface{}) (Schema, error) {
+	typ := reflect.TypeOf(config)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return Schema{}, errors.New("invalid config, should be struct")
+	}
+	return Schema{Fields: configor.inspectFields(typ, "", nil, map[reflect.Type]bool{})}, nil
+}
+
+// inspectFields returns schema fields of the struct type like walkFields, visiting holds types being inspected to stop recursion
+func (configor *Configor) inspectFields(typ reflect.Type, parentPath string, names []string, visiting map[reflect.Type]bool) []SchemaField {
+	visiting[typ] = true
+	defer delete(visiting, typ)
+
+	var fields []SchemaField
+	for i := 0; i < typ.NumField(); i++ {
+		fieldStruct := typ.Field(i)
+		if fieldStruct.PkgPath != "" || fieldStruct.Tag.Get("configor") == "-" {
+			continue
+		}
+
+		fieldPath := joinPath(parentPath, fieldStruct.Name)
+		fieldNames := append(append([]string{}, names...), fieldStruct.Name)
+		field := SchemaField{
+			Path:     fieldPath,
+			EnvVar:   configor.getEnvName(fieldStruct, fieldNames),
+			Type:     fieldStruct.Type.String(),
+			Default:  configor.getDefault(fieldStruct),
+			Required: isRequiredTag(fieldStruct),
+		}
+
+		// ignore_prefix:"true" breaks the env name chain, names of nested fields start from the `env` tag of the field
+		if fieldStruct.Tag.Get("ignore_prefix") == "true" {
+			fieldNames = []string{ignorePrefix}
+			if envName := fieldStruct.Tag.Get("env"); envName != "" {
+				fieldNames = append(fieldNames, envName)
+			}
+		}
+
+		elemType := fieldStruct.Type
+		if !isNestedStruct(elemType) {
+			switch elemType.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				if isNestedStruct(elemType.Elem()) {
+					elemType, fieldPath, fieldNames = elemType.Elem(), fieldPath+"[*]", append(fieldNames, "*")
+				}
+			}
+		}
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if isNestedStruct(elemType) && !visiting[elemType] {
+			field.Children = configor.inspectFields(elemType, fieldPath, fieldNames, visiting)
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
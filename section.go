@@ -0,0 +1,109 @@
+package configor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RawConfig is the decoded configurations retained by Open, typed sections could be extracted from it on demand
+type RawConfig struct {
+	configor *Configor
+	data     map[string]interface{}
+}
+
+// Open will load configurations from files into a generic tree without decoding them into a struct
+func Open(files ...string) (*RawConfig, error) {
+	return New().Open(files...)
+}
+
+// Open will load configurations from files into a generic tree without decoding them into a struct, files are resolved
+// and merged like Load, use Section to extract typed sections from it
+func (configor *Configor) Open(files ...string) (*RawConfig, error) {
+	configor, err := configor.withEnvFile()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedFiles, missingFiles := configor.getConfigurations(configor.resolvePaths(files)...)
+	if len(missingFiles) > 0 && !configor.degrade {
+		return nil, missingFiles[0]
+	}
+
+	raw := &RawConfig{configor: configor, data: map[string]interface{}{}}
+	for _, file := range resolvedFiles {
+		data, format, err := configor.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var generic interface{}
+		if err := Decode(&generic, data, format); err != nil {
+			return nil, err
+		}
+
+		if values, ok := normalizeKeys(generic, nil).(map[string]interface{}); ok {
+			mergeMap(raw.data, values)
+		}
+	}
+	return raw, nil
+}
+
+// Section will decode the subtree at the dot-separated name (case insensitive) into config, then apply env, default and
+// required tags like Load, with the section name as env prefix, e.g. CONFIGOR_DATABASE_HOST for field Host of section database
+func (raw *RawConfig) Section(name string, config interface{}) error {
+	var value interface{} = raw.data
+	var names []string
+	for _, segment := range parsePath(name) {
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("section %v not found", name)
+		}
+
+		value = nil
+		for key, v := range values {
+			if strings.EqualFold(key, segment) {
+				value = v
+				break
+			}
+		}
+		names = append(names, segment)
+	}
+
+	if value != nil {
+		js, err := json.Marshal(normalizeKeys(value, reflectType(config)))
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(js, config); err != nil {
+			return err
+		}
+	}
+
+	result := &LoadResult{Sources: map[string]ValueSource{}, Warnings: &Warnings{}}
+	if err := raw.configor.processTags(config, result, "", names...); err != nil {
+		return err
+	}
+
+	if err := raw.configor.validate(config, result); err != nil {
+		return err
+	}
+
+	if len(result.errors) > 0 {
+		return result.errors
+	}
+	return nil
+}
+
+// mergeMap merges src into dst deeply, values of src have higher priority
+func mergeMap(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeMap(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,113 @@
+package configor
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// applySliceEnv sets elements of the slice from env with indices, e.g. CONFIGOR_HOSTS_1 for Hosts[1]. The slice from files (or
+// the whole-slice env) is the base, env with an index overrides the element in place, and indices beyond the length grow the
+// slice to the highest index, gaps are filled with zero values. Fields of struct elements, e.g. CONFIGOR_CONTACTS_2_EMAIL, are
+// set when walking into the elements, so they are only grown here
+func (configor *Configor) applySliceEnv(result *LoadResult, field reflect.Value, fieldPath string, fieldNames []string, format string, secret bool) (bool, error) {
+	typ := field.Type()
+	if typ == bytesType || hasConverter(typ) || configor.explicitEnvOnly {
+		return false, nil
+	}
+
+	maxIndex := configor.maxEnvIndex(result, fieldNames)
+
+	var fromEnv bool
+	if maxIndex >= field.Len() {
+		grown := reflect.MakeSlice(typ, maxIndex+1, maxIndex+1)
+		reflect.Copy(grown, field)
+		// allocate pointers to structs to walk into them
+		if typ.Elem().Kind() == reflect.Ptr && isNestedStruct(typ.Elem()) {
+			for i := field.Len(); i < grown.Len(); i++ {
+				grown.Index(i).Set(reflect.New(typ.Elem().Elem()))
+			}
+		}
+		field.Set(grown)
+		fromEnv = true
+	}
+
+	if isNestedStruct(typ.Elem()) {
+		return fromEnv, nil
+	}
+
+	for i := 0; i < field.Len(); i++ {
+		names := append(append([]string{}, fieldNames...), strconv.Itoa(i))
+		if value, ok := configor.lookupEnv(configor.getEnvNames(names), false); ok && value != Default {
+			set, err := configor.setEnv(result, field.Index(i), fmt.Sprintf("%v[%d]", fieldPath, i), value, format, secret)
+			if err != nil {
+				return fromEnv, err
+			}
+			fromEnv = fromEnv || set
+		}
+	}
+	return fromEnv, nil
+}
+
+// maxEnvIndex returns the highest index of env set for elements of the slice with names, -1 if there is none. Envs with indices
+// greater than the max slice index are ignored with warnings, as the slice is grown to the highest index, and they could be
+// unrelated envs sharing the prefix
+func (configor *Configor) maxEnvIndex(result *LoadResult, names []string) int {
+	// format env names with a placeholder of the index, then match env by the part before it
+	const placeholder = "\x01"
+	maxIndex := -1
+	for _, envName := range configor.getEnvNames(append(append([]string{}, names...), placeholder)) {
+		prefix, _, ok := strings.Cut(envName, placeholder)
+		if !ok {
+			continue
+		}
+
+		for _, name := range configor.environFunc() {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+
+			digits := name[len(prefix):]
+			if end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+				digits = digits[:end]
+			}
+			if digits == "" {
+				continue
+			}
+			if value, ok := configor.lookupEnvFunc(name); !ok || value == "" {
+				continue
+			}
+
+			index, err := strconv.Atoi(digits)
+			if err != nil || index > configor.maxSliceIndex {
+				configor.warn(result, fmt.Errorf("env %v is ignored, its index exceeds the max slice index %d", name, configor.maxSliceIndex))
+				continue
+			}
+			if index > maxIndex {
+				maxIndex = index
+			}
+		}
+	}
+	return maxIndex
+}
+
+// WithMaxSliceIndex set the max index of slice elements set from env (e.g. CONFIGOR_HOSTS_1) or overrides (e.g. hosts.1), as
+// slices are grown to the index, default is 1024
+func WithMaxSliceIndex(max int) Option {
+	return func(configor *Configor) {
+		configor.maxSliceIndex = max
+	}
+}
+
+// environ returns names of envs of the process
+func environ() []string {
+	var names []string
+	for _, env := range os.Environ() {
+		if name, _, ok := strings.Cut(env, "="); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
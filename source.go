@@ -0,0 +1,111 @@
+package configor
+
+import (
+	"fmt"
+	"io/ioutil"
+)
+
+// Source is where configurations are read from, returns the content and its format (yaml, toml or json, detected if blank)
+type Source interface {
+	Read() (data []byte, format string, err error)
+}
+
+// FileSource is a local configuration file, its env-specific and example files are resolved like files passed to Load
+type FileSource string
+
+// Read returns content of the file, with format from its extension (or the format registered with RegisterExtension)
+func (file FileSource) Read() ([]byte, string, error) {
+	data, err := ioutil.ReadFile(string(file))
+	return data, fileFormat(string(file)), err
+}
+
+// URLSource is a remote configuration fetched with HTTP GET, with auth and headers of the Configor when loaded by it
+type URLSource string
+
+// Read fetches content of the URL, with format from its extension or content type
+func (url URLSource) Read() ([]byte, string, error) {
+	return New().fetch(string(url))
+}
+
+// LoadSources will unmarshal configurations to struct from sources, earlier sources have higher priority like files of Load
+func LoadSources(config interface{}, sources ...Source) error {
+	return New().LoadSources(config, sources...)
+}
+
+// LoadSources will unmarshal configurations to struct from a mixed list of sources, e.g. files, URLs and custom sources,
+// env and example files are only resolved for file sources. Sources are merged in order like files of Load
+func (configor *Configor) LoadSources(config interface{}, sources ...Source) error {
+	if _, err := configor.loadConfig(config, sources...); err != nil {
+		return err
+	}
+
+	configor.state.mutex.Lock()
+	configor.state.config, configor.state.keys = config, nil
+	configor.state.mutex.Unlock()
+	return nil
+}
+
+// fileSources converts files passed to Load to sources
+func fileSources(files []string) []Source {
+	sources := make([]Source, len(files))
+	for i, file := range files {
+		if isURL(file) {
+			sources[i] = URLSource(file)
+		} else {
+			sources[i] = FileSource(file)
+		}
+	}
+	return sources
+}
+
+// getSources returns sources to load like getConfigurations, file sources are replaced with their env and example files
+func (configor *Configor) getSources(sources []Source) ([]Source, []error) {
+	var results []Source
+	var errs []error
+	for i := len(sources) - 1; i >= 0; i-- {
+		file, ok := sources[i].(FileSource)
+		if !ok {
+			results = append(results, sources[i])
+			continue
+		}
+
+		files, missingFiles := configor.getConfigurations(string(file))
+		if example := getFileWithENV(string(file), "example"); len(files) == 1 && files[0] == example {
+			configor.infof("Failed to find configuration %v, using example file %v", file, example)
+		}
+		results = append(results, fileSources(files)...)
+		errs = append(errs, missingFiles...)
+	}
+	return results, errs
+}
+
+// readSource returns content and format of the source, URLs are fetched with auth and headers of the Configor
+func (configor *Configor) readSource(source Source) ([]byte, string, error) {
+	switch source := source.(type) {
+	case FileSource:
+		return configor.readFile(string(source))
+	case URLSource:
+		return configor.fetch(string(source))
+	case EnvSource:
+		value, _ := configor.lookupEnvFunc(source.Name)
+		return []byte(value), source.Format, nil
+	default:
+		return source.Read()
+	}
+}
+
+// sourceName returns the name of the source used in logs and errors, passwords in URLs are redacted
+func sourceName(source Source) string {
+	switch source := source.(type) {
+	case FileSource:
+		return redactURL(string(source))
+	case URLSource:
+		return redactURL(string(source))
+	case EnvSource:
+		return "env " + source.Name
+	case fmt.Stringer:
+		return source.String()
+	default:
+		return fmt.Sprintf("%T", source)
+	}
+}
@@ -0,0 +1,181 @@
+// Package appconfig loads configurations from AWS AppConfig
+package appconfig
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/jinzhu/configor"
+)
+
+// Client is the subset of *appconfigdata.Client used by Source
+type Client interface {
+	StartConfigurationSession(ctx context.Context, params *appconfigdata.StartConfigurationSessionInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error)
+	GetLatestConfiguration(ctx context.Context, params *appconfigdata.GetLatestConfigurationInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error)
+}
+
+// Source retrieves a configuration profile from AWS AppConfig
+type Source struct {
+	client       Client
+	application  string
+	environment  string
+	profile      string
+	pollInterval time.Duration
+	configor     *configor.Configor
+
+	mutex       sync.Mutex
+	token       *string
+	data        []byte
+	contentType string
+}
+
+// Option is used to customize a Source
+type Option func(*Source)
+
+// WithPollInterval set the interval to poll the latest configuration when watching, default is 1 minute
+func WithPollInterval(interval time.Duration) Option {
+	return func(source *Source) {
+		source.pollInterval = interval
+	}
+}
+
+// WithConfigor set the Configor to apply env and default tags with after decoding, so its options (e.g. env prefixes) are used,
+// default is configor.New()
+func WithConfigor(loader *configor.Configor) Option {
+	return func(source *Source) {
+		source.configor = loader
+	}
+}
+
+// New initialize a Source for the application, environment and configuration profile
+func New(client Client, application, environment, profile string, opts ...Option) *Source {
+	source := &Source{
+		client:       client,
+		application:  application,
+		environment:  environment,
+		profile:      profile,
+		pollInterval: time.Minute,
+		configor:     configor.New(),
+	}
+	for _, opt := range opts {
+		opt(source)
+	}
+	return source
+}
+
+// Load will retrieve the latest configuration and unmarshal it to config, then apply env and default tags with the Configor of
+// the Source like configor.Load
+func (source *Source) Load(ctx context.Context, config interface{}) error {
+	if _, err := source.fetch(ctx); err != nil {
+		return err
+	}
+	return source.decode(config)
+}
+
+// Watch will poll the latest configuration every poll interval until ctx is done, when it's changed, the new configuration
+// will be loaded to config and onChange will be called, onChange will be called with the error if failed to load it.
+// config is updated from the watching goroutine, use onChange to synchronize access to it
+func (source *Source) Watch(ctx context.Context, config interface{}, onChange func(config interface{}, err error)) {
+	go func() {
+		ticker := time.NewTicker(source.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := source.fetch(ctx)
+				if err == nil && !changed {
+					continue
+				}
+				if err == nil {
+					err = source.decode(config)
+				}
+				onChange(config, err)
+			}
+		}
+	}()
+}
+
+// fetch retrieves the latest configuration, returns true if it's changed
+func (source *Source) fetch(ctx context.Context) (bool, error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+
+	if source.token == nil {
+		output, err := source.client.StartConfigurationSession(ctx, &appconfigdata.StartConfigurationSessionInput{
+			ApplicationIdentifier:          aws.String(source.application),
+			EnvironmentIdentifier:          aws.String(source.environment),
+			ConfigurationProfileIdentifier: aws.String(source.profile),
+		})
+		if err != nil {
+			return false, err
+		}
+		source.token = output.InitialConfigurationToken
+	}
+
+	output, err := source.client.GetLatestConfiguration(ctx, &appconfigdata.GetLatestConfigurationInput{
+		ConfigurationToken: source.token,
+	})
+	if err != nil {
+		return false, err
+	}
+	source.token = output.NextPollConfigurationToken
+
+	// blank configuration means it's not changed since last call
+	if len(output.Configuration) == 0 {
+		if source.data == nil {
+			return false, errors.New("no configuration returned from AppConfig")
+		}
+		return false, nil
+	}
+
+	source.data = output.Configuration
+	source.contentType = aws.ToString(output.ContentType)
+	return true, nil
+}
+
+// decode unmarshals the latest configuration into a new value of config's type, and replace config with it if succeed
+func (source *Source) decode(config interface{}) error {
+	source.mutex.Lock()
+	data, format := source.data, getFormat(source.contentType)
+	source.mutex.Unlock()
+
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr {
+		return errors.New("invalid config, should be pointer")
+	}
+
+	result := reflect.New(configValue.Elem().Type())
+	if err := configor.Decode(result.Interface(), data, format); err != nil {
+		return err
+	}
+
+	if err := source.configor.Load(result.Interface()); err != nil {
+		return err
+	}
+
+	configValue.Elem().Set(result.Elem())
+	return nil
+}
+
+// getFormat returns the configor format of AppConfig's content type, blank means detect it
+func getFormat(contentType string) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "json"
+	case strings.Contains(contentType, "yaml"):
+		return "yaml"
+	case strings.Contains(contentType, "toml"):
+		return "toml"
+	default:
+		return ""
+	}
+}
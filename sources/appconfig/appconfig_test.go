@@ -0,0 +1,151 @@
+package appconfig_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/appconfigdata"
+	"github.com/jinzhu/configor"
+	"github.com/jinzhu/configor/sources/appconfig"
+)
+
+type fakeClient struct {
+	sessions      int
+	tokens        []string
+	configuration []string
+	contentType   string
+	err           error
+}
+
+func (client *fakeClient) StartConfigurationSession(ctx context.Context, params *appconfigdata.StartConfigurationSessionInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.StartConfigurationSessionOutput, error) {
+	client.sessions++
+	if aws.ToString(params.ApplicationIdentifier) != "app" || aws.ToString(params.EnvironmentIdentifier) != "prod" || aws.ToString(params.ConfigurationProfileIdentifier) != "main" {
+		return nil, errors.New("unknown configuration profile")
+	}
+	return &appconfigdata.StartConfigurationSessionOutput{InitialConfigurationToken: aws.String("token0")}, nil
+}
+
+func (client *fakeClient) GetLatestConfiguration(ctx context.Context, params *appconfigdata.GetLatestConfigurationInput, optFns ...func(*appconfigdata.Options)) (*appconfigdata.GetLatestConfigurationOutput, error) {
+	if client.err != nil {
+		return nil, client.err
+	}
+
+	token := aws.ToString(params.ConfigurationToken)
+	client.tokens = append(client.tokens, token)
+
+	output := &appconfigdata.GetLatestConfigurationOutput{
+		ContentType:                aws.String(client.contentType),
+		NextPollConfigurationToken: aws.String(fmt.Sprintf("token%d", len(client.tokens))),
+	}
+	// configurations are returned in order, blank after all of them are returned
+	if len(client.configuration) > 0 {
+		output.Configuration = []byte(client.configuration[0])
+		client.configuration = client.configuration[1:]
+	}
+	return output, nil
+}
+
+type appConfig struct {
+	Name string `default:"app"`
+	Port int
+}
+
+func TestLoad(t *testing.T) {
+	client := &fakeClient{configuration: []string{`{"Port": 8080}`}, contentType: "application/json"}
+	source := appconfig.New(client, "app", "prod", "main")
+
+	var config appConfig
+	if err := source.Load(context.Background(), &config); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if config.Port != 8080 || config.Name != "app" {
+		t.Errorf("configurations should be loaded with defaults, but got %#v", config)
+	}
+
+	// the session is started once, later calls use the next poll token
+	client.configuration = []string{`{"Port": 9090}`}
+	if err := source.Load(context.Background(), &config); err != nil || config.Port != 9090 {
+		t.Errorf("the latest configuration should be loaded, but got %#v, %v", config, err)
+	}
+	if client.sessions != 1 || len(client.tokens) != 2 || client.tokens[0] != "token0" || client.tokens[1] != "token1" {
+		t.Errorf("tokens should be chained in one session, but got %v sessions with tokens %v", client.sessions, client.tokens)
+	}
+}
+
+func TestLoadWithConfigor(t *testing.T) {
+	t.Setenv("MYAPP_NAME", "from_env")
+
+	client := &fakeClient{configuration: []string{"port: 8080\n"}, contentType: "application/x-yaml"}
+	source := appconfig.New(client, "app", "prod", "main", appconfig.WithConfigor(configor.New(configor.WithPrefixes("MYAPP"))))
+
+	var config appConfig
+	if err := source.Load(context.Background(), &config); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if config.Port != 8080 || config.Name != "from_env" {
+		t.Errorf("env should be applied with options of the Configor, but got %#v", config)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	var config appConfig
+	if err := appconfig.New(&fakeClient{}, "app", "prod", "main").Load(context.Background(), &config); err == nil {
+		t.Errorf("Should got error when no configuration returned")
+	}
+
+	if err := appconfig.New(&fakeClient{}, "app", "prod", "unknown").Load(context.Background(), &config); err == nil {
+		t.Errorf("Should got error when failed to start session")
+	}
+
+	client := &fakeClient{err: errors.New("throttled")}
+	if err := appconfig.New(client, "app", "prod", "main").Load(context.Background(), &config); err == nil || err.Error() != "throttled" {
+		t.Errorf("Should got error of the client, but got %v", err)
+	}
+
+	client = &fakeClient{configuration: []string{`{"Port": "http"}`}, contentType: "application/json"}
+	config.Port = 80
+	if err := appconfig.New(client, "app", "prod", "main").Load(context.Background(), &config); err == nil || config.Port != 80 {
+		t.Errorf("config should be kept when failed to decode, but got %#v, %v", config, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	client := &fakeClient{configuration: []string{`{"Port": 8080}`, `{"Port": 9090}`}, contentType: "application/json"}
+	source := appconfig.New(client, "app", "prod", "main", appconfig.WithPollInterval(10*time.Millisecond))
+
+	var config appConfig
+	if err := source.Load(context.Background(), &config); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan int, 10)
+	source.Watch(ctx, &config, func(config interface{}, err error) {
+		if err != nil {
+			t.Errorf("No error should happen when reload configurations, but got %v", err)
+		}
+		changes <- config.(*appConfig).Port
+	})
+
+	select {
+	case port := <-changes:
+		if port != 9090 {
+			t.Errorf("changed configuration should be loaded, but got %v", port)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("changed configuration should be loaded when watching")
+	}
+
+	// unchanged configurations are not reloaded
+	select {
+	case port := <-changes:
+		t.Errorf("unchanged configuration shouldn't be reloaded, but got %v", port)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
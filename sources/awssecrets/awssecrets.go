@@ -0,0 +1,132 @@
+// Package awssecrets resolves secrets from AWS SSM Parameter Store and Secrets Manager for configor
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/jinzhu/configor"
+)
+
+// SSMClient is the subset of *ssm.Client used by SSMResolver
+type SSMClient interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// SSMResolver resolves parameters referenced as `ssm:"<name>"`, e.g. `ssm:"/myapp/db/password"`, SecureString parameters are decrypted
+type SSMResolver struct {
+	client SSMClient
+}
+
+// NewSSMResolver initialize a SSMResolver, use it with configor.WithSecretResolver("ssm", awssecrets.NewSSMResolver(ssm.NewFromConfig(awsConfig)))
+func NewSSMResolver(client SSMClient) *SSMResolver {
+	return &SSMResolver{client: client}
+}
+
+// Resolve returns the value of the parameter
+func (resolver *SSMResolver) Resolve(ref string) (string, error) {
+	output, err := resolver.client.GetParameter(context.Background(), &ssm.GetParameterInput{
+		Name:           aws.String(ref),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+	if output.Parameter == nil || output.Parameter.Value == nil {
+		return "", fmt.Errorf("ssm parameter %v not found", ref)
+	}
+	return *output.Parameter.Value, nil
+}
+
+// SecretsManagerClient is the subset of *secretsmanager.Client used by SecretsManagerResolver
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerResolver resolves secrets referenced as `secretsmanager:"<secret id>"` or `secretsmanager:"<secret id>#<key>"`,
+// the key is used to get a value from secrets stored as JSON objects
+type SecretsManagerResolver struct {
+	client SecretsManagerClient
+}
+
+// NewSecretsManagerResolver initialize a SecretsManagerResolver, use it with
+// configor.WithSecretResolver("secretsmanager", awssecrets.NewSecretsManagerResolver(secretsmanager.NewFromConfig(awsConfig)))
+func NewSecretsManagerResolver(client SecretsManagerClient) *SecretsManagerResolver {
+	return &SecretsManagerResolver{client: client}
+}
+
+// Resolve returns the secret, or the value of key if the secret is a JSON object
+func (resolver *SecretsManagerResolver) Resolve(ref string) (string, error) {
+	secretID, key, hasKey := strings.Cut(ref, "#")
+	secret, err := getSecret(context.Background(), resolver.client, secretID)
+	if err != nil || !hasKey {
+		return string(secret), err
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(secret, &values); err != nil {
+		return "", fmt.Errorf("secret %v is not a JSON object: %v", secretID, err)
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("key %v not found in secret %v", key, secretID)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// LoadSecret will decode the secret (JSON, YAML or TOML) into config, then apply env and default tags like configor.Load
+func LoadSecret(ctx context.Context, client SecretsManagerClient, secretID string, config interface{}) error {
+	return LoadSecretWith(ctx, configor.New(), client, secretID, config)
+}
+
+// LoadSecretWith will decode the secret into config like LoadSecret, then apply env and default tags with loader, so its
+// options (e.g. env prefixes) are used
+func LoadSecretWith(ctx context.Context, loader *configor.Configor, client SecretsManagerClient, secretID string, config interface{}) error {
+	secret, err := getSecret(ctx, client, secretID)
+	if err != nil {
+		return err
+	}
+
+	configValue := reflect.ValueOf(config)
+	if configValue.Kind() != reflect.Ptr {
+		return errors.New("invalid config, should be pointer")
+	}
+
+	result := reflect.New(configValue.Elem().Type())
+	if err := configor.Decode(result.Interface(), secret, ""); err != nil {
+		return err
+	}
+
+	if err := loader.Load(result.Interface()); err != nil {
+		return err
+	}
+
+	configValue.Elem().Set(result.Elem())
+	return nil
+}
+
+func getSecret(ctx context.Context, client SecretsManagerClient, secretID string) ([]byte, error) {
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case output.SecretString != nil:
+		return []byte(*output.SecretString), nil
+	case output.SecretBinary != nil:
+		return output.SecretBinary, nil
+	default:
+		return nil, fmt.Errorf("secret %v not found", secretID)
+	}
+}
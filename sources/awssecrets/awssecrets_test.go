@@ -0,0 +1,115 @@
+package awssecrets_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/jinzhu/configor"
+	"github.com/jinzhu/configor/sources/awssecrets"
+)
+
+type fakeSSMClient map[string]string
+
+func (client fakeSSMClient) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	if !aws.ToBool(params.WithDecryption) {
+		return nil, errors.New("parameters should be decrypted")
+	}
+
+	name := aws.ToString(params.Name)
+	if name == "/error" {
+		return nil, errors.New("access denied")
+	}
+	value, ok := client[name]
+	if !ok {
+		return &ssm.GetParameterOutput{}, nil
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: aws.String(name), Value: aws.String(value)}}, nil
+}
+
+func TestSSMResolver(t *testing.T) {
+	resolver := awssecrets.NewSSMResolver(fakeSSMClient{"/myapp/db/password": "secret"})
+
+	if value, err := resolver.Resolve("/myapp/db/password"); err != nil || value != "secret" {
+		t.Errorf("parameter should be resolved, but got %v, %v", value, err)
+	}
+
+	for _, ref := range []string{"/myapp/unknown", "/error"} {
+		if _, err := resolver.Resolve(ref); err == nil {
+			t.Errorf("Should got error when resolve %v", ref)
+		}
+	}
+}
+
+type fakeSecretsManagerClient map[string]*secretsmanager.GetSecretValueOutput
+
+func (client fakeSecretsManagerClient) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	output, ok := client[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, errors.New("secret not found")
+	}
+	return output, nil
+}
+
+func TestSecretsManagerResolver(t *testing.T) {
+	resolver := awssecrets.NewSecretsManagerResolver(fakeSecretsManagerClient{
+		"plain":  {SecretString: aws.String("token")},
+		"json":   {SecretString: aws.String(`{"password": "secret", "port": 5432}`)},
+		"binary": {SecretBinary: []byte("binary")},
+		"blank":  {},
+	})
+
+	for ref, expected := range map[string]string{"plain": "token", "json#password": "secret", "json#port": "5432", "binary": "binary"} {
+		if value, err := resolver.Resolve(ref); err != nil || value != expected {
+			t.Errorf("%v should be resolved to %v, but got %v, %v", ref, expected, value, err)
+		}
+	}
+
+	for _, ref := range []string{"unknown", "blank", "plain#password", "json#user"} {
+		if _, err := resolver.Resolve(ref); err == nil {
+			t.Errorf("Should got error when resolve %v", ref)
+		}
+	}
+}
+
+func TestLoadSecret(t *testing.T) {
+	type secretConfig struct {
+		Name     string `default:"app"`
+		Password string
+	}
+
+	client := fakeSecretsManagerClient{
+		"config":  {SecretString: aws.String(`{"Password": "secret"}`)},
+		"invalid": {SecretString: aws.String(`{"Password": `)},
+	}
+
+	var config secretConfig
+	if err := awssecrets.LoadSecret(context.Background(), client, "config", &config); err != nil {
+		t.Fatalf("No error should happen when load secret, but got %v", err)
+	}
+	if config.Password != "secret" || config.Name != "app" {
+		t.Errorf("secret should be loaded with defaults, but got %#v", config)
+	}
+
+	t.Setenv("MYAPP_NAME", "from_env")
+	var withConfigor secretConfig
+	if err := awssecrets.LoadSecretWith(context.Background(), configor.New(configor.WithPrefixes("MYAPP")), client, "config", &withConfigor); err != nil {
+		t.Fatalf("No error should happen when load secret, but got %v", err)
+	}
+	if withConfigor.Password != "secret" || withConfigor.Name != "from_env" {
+		t.Errorf("env should be applied with options of the Configor, but got %#v", withConfigor)
+	}
+
+	for _, secretID := range []string{"unknown", "invalid"} {
+		if err := awssecrets.LoadSecret(context.Background(), client, secretID, &config); err == nil {
+			t.Errorf("Should got error when load secret %v", secretID)
+		}
+	}
+	if config.Password != "secret" {
+		t.Errorf("config should be kept when failed to load secret, but got %#v", config)
+	}
+}
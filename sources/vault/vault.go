@@ -0,0 +1,53 @@
+// Package vault resolves secrets from HashiCorp Vault for configor
+package vault
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// Reader is the subset of *api.Logical used by Resolver
+type Reader interface {
+	Read(path string) (*api.Secret, error)
+}
+
+// Resolver resolves secrets referenced as `vault:"<path>#<key>"`, e.g. `vault:"secret/data/myapp#password"`,
+// both KV version 1 and 2 engines are supported
+type Resolver struct {
+	reader Reader
+}
+
+// NewResolver initialize a Resolver, use it with configor.WithSecretResolver("vault", vault.NewResolver(client.Logical()))
+func NewResolver(reader Reader) *Resolver {
+	return &Resolver{reader: reader}
+}
+
+// Resolve reads the secret at path and returns the value of key
+func (resolver *Resolver) Resolve(ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok {
+		return "", fmt.Errorf("invalid vault reference %v, should be <path>#<key>", ref)
+	}
+
+	secret, err := resolver.reader.Read(path)
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("vault secret %v not found", path)
+	}
+
+	data := secret.Data
+	// KV version 2 engine wraps values in data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %v not found in vault secret %v", key, path)
+	}
+	return fmt.Sprint(value), nil
+}
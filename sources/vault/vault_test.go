@@ -0,0 +1,113 @@
+package vault_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/jinzhu/configor"
+	"github.com/jinzhu/configor/sources/vault"
+)
+
+type fakeReader struct {
+	secrets map[string]*api.Secret
+	paths   []string
+}
+
+func (reader *fakeReader) Read(path string) (*api.Secret, error) {
+	reader.paths = append(reader.paths, path)
+	if path == "secret/forbidden" {
+		return nil, errors.New("permission denied")
+	}
+	return reader.secrets[path], nil
+}
+
+func newFakeReader() *fakeReader {
+	return &fakeReader{secrets: map[string]*api.Secret{
+		// KV version 1
+		"secret/myapp": {Data: map[string]interface{}{"password": "v1_secret", "port": 5432}},
+		// KV version 2 wraps values in data, with metadata alongside
+		"secret/data/myapp": {Data: map[string]interface{}{
+			"data":     map[string]interface{}{"password": "v2_secret", "token": "v2_token"},
+			"metadata": map[string]interface{}{"version": 3},
+		}},
+		"secret/empty": {},
+	}}
+}
+
+func TestResolve(t *testing.T) {
+	reader := newFakeReader()
+	resolver := vault.NewResolver(reader)
+
+	for ref, expected := range map[string]string{
+		"secret/myapp#password":      "v1_secret",
+		"secret/myapp#port":          "5432",
+		"secret/data/myapp#password": "v2_secret",
+		"secret/data/myapp#token":    "v2_token",
+	} {
+		if value, err := resolver.Resolve(ref); err != nil || value != expected {
+			t.Errorf("%v should be resolved to %v, but got %v, %v", ref, expected, value, err)
+		}
+	}
+
+	// the path before # is read as is, the key after it is looked up in the secret
+	if len(reader.paths) != 4 || reader.paths[0] == "" {
+		t.Errorf("paths should be read once for each ref, but got %v", reader.paths)
+	}
+	for _, path := range reader.paths {
+		if path != "secret/myapp" && path != "secret/data/myapp" {
+			t.Errorf("path should be read without the key, but got %v", path)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	reader := newFakeReader()
+	resolver := vault.NewResolver(reader)
+
+	for _, ref := range []string{
+		// missing key
+		"secret/myapp",
+		// read error
+		"secret/forbidden#password",
+		// secret not found
+		"secret/unknown#password",
+		// secret without data
+		"secret/empty#password",
+		// key not found
+		"secret/myapp#token",
+		"secret/data/myapp#version",
+	} {
+		if _, err := resolver.Resolve(ref); err == nil {
+			t.Errorf("Should got error when resolve %v", ref)
+		}
+	}
+
+	if len(reader.paths) != 5 {
+		t.Errorf("invalid refs shouldn't be read, but got %v", reader.paths)
+	}
+}
+
+func TestLoadWithResolver(t *testing.T) {
+	type vaultConfig struct {
+		Password string `vault:"secret/data/myapp#password"`
+		Token    string `vault:"secret/data/myapp#token"`
+	}
+
+	t.Setenv("CONFIGOR_TOKEN", "env_token")
+
+	var config vaultConfig
+	if err := configor.New(configor.WithSecretResolver("vault", vault.NewResolver(newFakeReader()))).Load(&config); err != nil {
+		t.Fatalf("No error should happen when load with vault resolver, but got %v", err)
+	}
+	if config.Password != "v2_secret" || config.Token != "env_token" {
+		t.Errorf("blank fields should be resolved from vault after env, but got %#v", config)
+	}
+
+	type missingConfig struct {
+		Password string `vault:"secret/forbidden#password"`
+	}
+	if err := configor.New(configor.WithSecretResolver("vault", vault.NewResolver(newFakeReader()))).Load(&missingConfig{}); err == nil {
+		t.Errorf("Should got error when failed to resolve from vault")
+	}
+}
@@ -0,0 +1,168 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// WithYAMLStyle set the style of collections when saving yaml, e.g. yaml.FlowStyle of gopkg.in/yaml.v3 to save them compactly
+// like `[a, b]`, fields tagged with `yaml_style:"flow"` or `yaml_style:"block"` override it
+func WithYAMLStyle(style yamlv3.Style) Option {
+	return func(configor *Configor) {
+		configor.yamlStyle = style
+	}
+}
+
+// encodeYAML writes config in yaml to w, collections are saved in the style option or their `yaml_style` tags
+func (configor *Configor) encodeYAML(w io.Writer, config interface{}) error {
+	var node yamlv3.Node
+	if err := node.Encode(config); err != nil {
+		return err
+	}
+	applyYAMLStyle(&node, reflect.TypeOf(config), configor.yamlStyle)
+
+	encoder := yamlv3.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// hasYAMLStyle returns true if the yaml style is set or any field of config is tagged with `yaml_style`
+func (configor *Configor) hasYAMLStyle(config interface{}) bool {
+	if configor.yamlStyle != 0 {
+		return true
+	}
+
+	var found bool
+	walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		found = found || fieldStruct.Tag.Get("yaml_style") != ""
+		return nil
+	})
+	return found
+}
+
+// applyYAMLStyle sets the style of collection nodes, typ is the type encoded to the node, used to find `yaml_style` tags
+func applyYAMLStyle(node *yamlv3.Node, typ reflect.Type, style yamlv3.Style) {
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Interface) {
+		if typ.Kind() == reflect.Interface {
+			typ = nil
+		} else {
+			typ = typ.Elem()
+		}
+	}
+
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		node.Style = style
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var valueType reflect.Type
+			valueStyle := style
+			if typ != nil && typ.Kind() == reflect.Struct {
+				if fieldStruct, ok := findYAMLField(typ, node.Content[i].Value); ok {
+					valueType = fieldStruct.Type
+					switch fieldStruct.Tag.Get("yaml_style") {
+					case "flow":
+						valueStyle = yamlv3.FlowStyle
+					case "block":
+						valueStyle = 0
+					}
+				}
+			} else if typ != nil && typ.Kind() == reflect.Map {
+				valueType = typ.Elem()
+			}
+			applyYAMLStyle(node.Content[i+1], valueType, valueStyle)
+		}
+	case yamlv3.SequenceNode:
+		node.Style = style
+		var elemType reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elemType = typ.Elem()
+		}
+		for _, item := range node.Content {
+			applyYAMLStyle(item, elemType, style)
+		}
+	}
+}
+
+// findYAMLField returns the field encoded as key, fields of inlined structs are promoted
+func findYAMLField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		fieldStruct := typ.Field(i)
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		tags := strings.Split(fieldStruct.Tag.Get("yaml"), ",")
+		if len(tags) > 1 && tags[1] == "inline" {
+			inlineType := fieldStruct.Type
+			if inlineType.Kind() == reflect.Ptr {
+				inlineType = inlineType.Elem()
+			}
+			if inlineType.Kind() == reflect.Struct {
+				if inlineField, ok := findYAMLField(inlineType, key); ok {
+					return inlineField, true
+				}
+			}
+			continue
+		}
+
+		name := tags[0]
+		if name == "" {
+			name = strings.ToLower(fieldStruct.Name)
+		}
+		if name == key {
+			return fieldStruct, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// WithJSONIndent set the prefix and indent when saving json, e.g. WithJSONIndent("", "  ")
+func WithJSONIndent(prefix, indent string) Option {
+	return func(configor *Configor) {
+		configor.jsonPrefix, configor.jsonIndent = prefix, indent
+	}
+}
+
+// WithJSONSortKeys sort keys of objects alphabetically when saving json, including fields of structs, for reproducible output
+func WithJSONSortKeys(sortKeys bool) Option {
+	return func(configor *Configor) {
+		configor.jsonSortKeys = sortKeys
+	}
+}
+
+// marshalJSON marshals config to json with the indent and sort keys options
+func (configor *Configor) marshalJSON(config interface{}) ([]byte, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if configor.jsonSortKeys {
+		// maps are encoded with sorted keys, numbers are kept as is
+		var generic interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&generic); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(generic); err != nil {
+			return nil, err
+		}
+	}
+
+	if configor.jsonPrefix == "" && configor.jsonIndent == "" {
+		return data, nil
+	}
+
+	var buffer bytes.Buffer
+	err = json.Indent(&buffer, data, configor.jsonPrefix, configor.jsonIndent)
+	return buffer.Bytes(), err
+}
@@ -0,0 +1,54 @@
+// Package testutil provides helpers to test code loading configurations with configor
+package testutil
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jinzhu/configor"
+)
+
+// WithEnv sets envs and runs fn, envs are restored to their original values (or unset) when the test finishes
+func WithEnv(t *testing.T, env map[string]string, fn func()) {
+	t.Helper()
+
+	for name, value := range env {
+		name := name
+		if original, ok := os.LookupEnv(name); ok {
+			t.Cleanup(func() { os.Setenv(name, original) })
+		} else {
+			t.Cleanup(func() { os.Unsetenv(name) })
+		}
+
+		if err := os.Setenv(name, value); err != nil {
+			t.Fatalf("failed to set env %v: %v", name, err)
+		}
+	}
+	fn()
+}
+
+// TempConfig writes content to a temporary configuration file with the format (yaml, yml, toml or json) as its extension,
+// and returns its path, the file is removed when the test finishes
+func TempConfig(t *testing.T, format string, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "config."+strings.TrimPrefix(format, "."))
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write configuration %v: %v", file, err)
+	}
+	return file
+}
+
+// MustLoad loads content in the format into a new value of T like configor.Load, the test fails immediately if failed to load it
+func MustLoad[T any](t *testing.T, content string, format string) T {
+	t.Helper()
+
+	var config T
+	if err := configor.Load(&config, TempConfig(t, format, content)); err != nil {
+		t.Fatalf("failed to load configuration: %v", err)
+	}
+	return config
+}
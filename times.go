@@ -0,0 +1,122 @@
+package configor
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// WithTimeLayout set the layout to parse timestamps of time.Time fields in files which are not RFC3339, e.g. "2006-01-02 15:04:05",
+// timestamps without time zone are parsed in UTC
+func WithTimeLayout(layout string) Option {
+	return func(configor *Configor) {
+		configor.timeLayout = layout
+	}
+}
+
+// naiveTimeLayouts are layouts of timestamps without time zone parsed from env and default tags, they're parsed in UTC,
+// matching TOML local datetimes, local dates and local times (on 0000-01-01)
+var naiveTimeLayouts = []string{"2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02", "15:04:05.999999999"}
+
+// parseTime parses value as RFC3339, or in layouts in UTC
+func parseTime(value string, layouts ...string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	for _, layout := range layouts {
+		if err == nil {
+			break
+		}
+		if parsed, parseErr := time.ParseInLocation(layout, value, time.UTC); parseErr == nil {
+			t, err = parsed, nil
+		}
+	}
+	return t, err
+}
+
+// normalizeTime returns the time in UTC if it's a TOML local datetime, local date or local time, which are decoded in local time zone
+func normalizeTime(t time.Time) time.Time {
+	switch t.Location().String() {
+	case "datetime-local", "date-local", "time-local":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	}
+	return t
+}
+
+// decodeTOML decodes TOML data into config, local datetimes, local dates and local times are decoded in UTC like other formats.
+// time.Time fields are decoded from text by the TOML decoder which loses their time zone names, so the data is normalized
+// and re-encoded if it contains them
+func decodeTOML(config interface{}, data []byte) error {
+	var generic map[string]interface{}
+	if err := toml.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+
+	if normalizeLocalTimes(generic) {
+		var buffer bytes.Buffer
+		if err := toml.NewEncoder(&buffer).Encode(generic); err != nil {
+			return err
+		}
+		data = buffer.Bytes()
+	}
+	return toml.Unmarshal(data, config)
+}
+
+// normalizeLocalTimes sets TOML local times in the generic value to UTC in place, returns true if any of them is changed
+func normalizeLocalTimes(value interface{}) bool {
+	var changed bool
+	switch values := value.(type) {
+	case map[string]interface{}:
+		for key, v := range values {
+			if t, ok := v.(time.Time); ok {
+				if normalized := normalizeTime(t); normalized.Location() != t.Location() {
+					values[key], changed = normalized, true
+				}
+			} else if normalizeLocalTimes(v) {
+				changed = true
+			}
+		}
+	case []map[string]interface{}:
+		for _, v := range values {
+			if normalizeLocalTimes(v) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, v := range values {
+			if t, ok := v.(time.Time); ok {
+				if normalized := normalizeTime(t); normalized.Location() != t.Location() {
+					values[i], changed = normalized, true
+				}
+			} else if normalizeLocalTimes(v) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// parseTimes returns a transformer converting timestamps of time.Time fields to RFC3339, timestamps not in RFC3339 are parsed
+// with layout if it's not blank, time-zone-naive timestamps are in UTC
+func parseTimes(layout string) func(value interface{}, typ reflect.Type) interface{} {
+	var layouts []string
+	if layout != "" {
+		layouts = append(layouts, layout)
+	}
+
+	return func(value interface{}, typ reflect.Type) interface{} {
+		if typ != timeType {
+			return value
+		}
+
+		switch v := value.(type) {
+		case string:
+			if t, err := parseTime(v, layouts...); err == nil {
+				return t.Format(time.RFC3339Nano)
+			}
+		case time.Time:
+			return normalizeTime(v).Format(time.RFC3339Nano)
+		}
+		return value
+	}
+}
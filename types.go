@@ -0,0 +1,169 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var typeRegistry = struct {
+	sync.RWMutex
+	factories map[string]func() interface{}
+}{factories: map[string]func() interface{}{}}
+
+// RegisterType registers the concrete type for interface fields tagged with `type:"<name>"`, or tagged with `discriminator:"<key>"`
+// whose data has the key set to name. Types for fields tagged with `oneof_struct:"<key>:<group>"` are registered as <group>.<name>.
+// proto is a value of the type, or a factory to create the value. e.g.
+//
+//	configor.RegisterType("s3", S3Config{})
+//	configor.RegisterType("jwt", func() interface{} { return &JWTProvider{} })
+//	configor.RegisterType("AuthProvider.oauth", OAuthConfig{})
+func RegisterType(name string, proto interface{}) {
+	factory, ok := proto.(func() interface{})
+	if !ok {
+		typ := reflect.TypeOf(proto)
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		factory = func() interface{} { return reflect.New(typ).Interface() }
+	}
+
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+	typeRegistry.factories[name] = factory
+}
+
+// newTypeValue creates a pointer of the registered type, which is assignable to the field
+func newTypeValue(name string, fieldPath string, fieldType reflect.Type) (reflect.Value, error) {
+	typeRegistry.RLock()
+	factory, ok := typeRegistry.factories[name]
+	typeRegistry.RUnlock()
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("type %v of %v is not registered", name, fieldPath)
+	}
+
+	value := reflect.ValueOf(factory())
+	if value.Kind() != reflect.Ptr {
+		ptr := reflect.New(value.Type())
+		ptr.Elem().Set(value)
+		value = ptr
+	}
+
+	if !value.Type().AssignableTo(fieldType) {
+		return reflect.Value{}, fmt.Errorf("type %v (%v) is not assignable to %v", name, value.Type(), fieldPath)
+	}
+	return value, nil
+}
+
+// prepareTypes sets interface fields tagged with `type` to new values created by their registered factories if they're nil,
+// so they could be decoded into, returns true if there are such fields or fields tagged with `discriminator`
+func prepareTypes(config interface{}) (bool, error) {
+	var found bool
+	err := walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		if field.Kind() != reflect.Interface {
+			return nil
+		}
+
+		if key, _ := getDiscriminator(fieldStruct); key != "" {
+			found = true
+			return nil
+		}
+
+		typeName := fieldStruct.Tag.Get("type")
+		if typeName == "" {
+			return nil
+		}
+
+		found = true
+		if !field.IsNil() {
+			return nil
+		}
+
+		value, err := newTypeValue(typeName, fieldPath, field.Type())
+		if err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	})
+	return found, err
+}
+
+// prepareDiscriminatedTypes sets interface fields tagged with `discriminator:"<key>"` to new values of the registered type named
+// by the key in data, data is the generic value with keys normalized to the json names of fields
+func prepareDiscriminatedTypes(value reflect.Value, data interface{}, parentPath string) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	values, ok := data.(map[string]interface{})
+	if !ok || value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldStruct := value.Field(i), value.Type().Field(i)
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		name := fieldStruct.Name
+		if jsonName := strings.Split(fieldStruct.Tag.Get("json"), ",")[0]; jsonName == "-" {
+			continue
+		} else if jsonName != "" {
+			name = jsonName
+		} else if fieldStruct.Anonymous {
+			// fields of embedded structs are promoted
+			if err := prepareDiscriminatedTypes(field, values, parentPath); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fieldData, ok := values[name]
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(parentPath, fieldStruct.Name)
+
+		if key, group := getDiscriminator(fieldStruct); key != "" && field.Kind() == reflect.Interface {
+			if fieldValues, ok := fieldData.(map[string]interface{}); ok {
+				if typeName, ok := fieldValues[key].(string); ok {
+					if group != "" {
+						typeName = group + "." + typeName
+					}
+
+					typeValue, err := newTypeValue(typeName, fieldPath, field.Type())
+					if err != nil {
+						return err
+					}
+
+					// keep values loaded from other files if the type is not changed
+					if field.IsNil() || field.Elem().Type() != typeValue.Type() {
+						field.Set(typeValue)
+					}
+				}
+			}
+		}
+
+		if err := prepareDiscriminatedTypes(field, fieldData, fieldPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDiscriminator returns the key of the discriminator and the group of registered types of the field, from tag
+// `discriminator:"<key>"` or `oneof_struct:"<key>:<group>"`
+func getDiscriminator(fieldStruct reflect.StructField) (key string, group string) {
+	if key := fieldStruct.Tag.Get("discriminator"); key != "" {
+		return key, ""
+	}
+
+	key, group, _ = strings.Cut(fieldStruct.Tag.Get("oneof_struct"), ":")
+	return key, group
+}
@@ -0,0 +1,84 @@
+package configor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal will extract the section at the dot-separated path (case insensitive) of the last loaded configuration into v,
+// v could be of a different type, fields are matched like decoding a json file of the section, e.g.
+//
+//	configor.Unmarshal("database", &dbConfig)
+func (configor *Configor) Unmarshal(path string, v interface{}) error {
+	configor.state.mutex.RLock()
+	config := configor.state.config
+	configor.state.mutex.RUnlock()
+
+	if config == nil {
+		return errors.New("no configuration loaded")
+	}
+
+	section, err := Field(config, path)
+	if err != nil {
+		return fmt.Errorf("failed to find %v: %v", path, err)
+	}
+
+	js, err := json.Marshal(section.Interface())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, v)
+}
+
+// Field returns the value at the dot-separated path (case insensitive) of config, e.g. DB.Port, Servers[0].Host, Labels[env],
+// pointers on the path are dereferenced, while the value at the path is returned as is, e.g. the *T of a pointer field.
+// Values of fields are settable if config is a pointer, while values in maps are copies
+func Field(config interface{}, path string) (reflect.Value, error) {
+	return getPath(reflect.ValueOf(config), parsePath(path))
+}
+
+// getPath navigates value by segments and returns the value at the path
+func getPath(value reflect.Value, segments []string) (reflect.Value, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, errors.New("nil value")
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		field := findField(value, segments[0])
+		if !field.IsValid() {
+			return reflect.Value{}, fmt.Errorf("field %v not found", segments[0])
+		}
+		return getPath(field, segments[1:])
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(segments[0])
+		if err != nil || index < 0 || index >= value.Len() {
+			return reflect.Value{}, fmt.Errorf("invalid index %v", segments[0])
+		}
+		return getPath(value.Index(index), segments[1:])
+	case reflect.Map:
+		key := reflect.New(value.Type().Key()).Elem()
+		if err := setValue(key, segments[0]); err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid key %v: %v", segments[0], err)
+		}
+
+		elem := value.MapIndex(key)
+		if !elem.IsValid() {
+			return reflect.Value{}, fmt.Errorf("key %v not found", segments[0])
+		}
+		return getPath(elem, segments[1:])
+	default:
+		return reflect.Value{}, fmt.Errorf("could not find %v in %v", strings.Join(segments, "."), value.Type())
+	}
+}
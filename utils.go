@@ -0,0 +1,148 @@
+package configor
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// reflectType returns the type of value, nil if value is nil
+func reflectType(value interface{}) reflect.Type {
+	if value == nil {
+		return nil
+	}
+	return reflect.TypeOf(value)
+}
+
+func isBlank(field reflect.Value) bool {
+	return reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface())
+}
+
+// isNestedStruct returns true if fields of the type should be walked into
+func isNestedStruct(typ reflect.Type) bool {
+	for {
+		// types with converters are set as a whole
+		if _, ok := getConverter(typ); ok {
+			return false
+		}
+
+		if typ.Kind() != reflect.Ptr {
+			break
+		}
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !reflect.PtrTo(typ).Implements(textUnmarshalerType)
+}
+
+// walkFields calls fn for each exported field of config recursively, including fields of structs in slices and maps, fields
+// tagged with `configor:"-"` are skipped with their nested fields,
+// parent is the struct contains the field, fieldPath is the dot-separated path of the field, fieldNames are the names used to generate the env name
+func walkFields(config interface{}, parentPath string, names []string, fn func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error) error {
+	configValue := reflect.Indirect(reflect.ValueOf(config))
+	if configValue.Kind() != reflect.Struct {
+		return errors.New("invalid config, should be struct")
+	}
+
+	if !configValue.CanAddr() {
+		addressable := reflect.New(configValue.Type()).Elem()
+		addressable.Set(configValue)
+		configValue = addressable
+	}
+
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		fieldStruct := configType.Field(i)
+		field := configValue.Field(i)
+
+		// skip unexported fields and fields excluded with `configor:"-"`
+		if fieldStruct.PkgPath != "" || fieldStruct.Tag.Get("configor") == "-" {
+			continue
+		}
+
+		fieldPath := joinPath(parentPath, fieldStruct.Name)
+		fieldNames := append(append([]string{}, names...), fieldStruct.Name)
+		if err := fn(field, fieldStruct, configValue, fieldPath, fieldNames); err != nil {
+			return err
+		}
+
+		// walk into structs set to interface fields
+		if field.Kind() == reflect.Interface && !field.IsNil() && field.Elem().Kind() == reflect.Ptr {
+			field = field.Elem()
+		} else if !isNestedStruct(field.Type()) && field.Kind() != reflect.Slice && field.Kind() != reflect.Map {
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+
+		// ignore_prefix:"true" breaks the env name chain, names of nested fields start from the `env` tag of the field
+		if fieldStruct.Tag.Get("ignore_prefix") == "true" {
+			fieldNames = []string{ignorePrefix}
+			if envName := fieldStruct.Tag.Get("env"); envName != "" {
+				fieldNames = append(fieldNames, envName)
+			}
+		}
+
+		if field.Kind() == reflect.Struct {
+			if err := walkFields(field.Addr().Interface(), fieldPath, fieldNames, fn); err != nil {
+				return err
+			}
+		}
+
+		if field.Kind() == reflect.Slice {
+			for i := 0; i < field.Len(); i++ {
+				// elements of pointers to structs are walked into the structs they point to
+				elem := field.Index(i)
+				for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+					elem = elem.Elem()
+				}
+				if isNestedStruct(elem.Type()) && elem.Kind() == reflect.Struct {
+					if err := walkFields(elem.Addr().Interface(), fmt.Sprintf("%v[%d]", fieldPath, i), append(fieldNames, fmt.Sprintf("%d", i)), fn); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		// keys of any type are formatted into the path and env name, e.g. Servers[1].Host, CONFIGOR_SERVERS_1_HOST
+		if field.Kind() == reflect.Map && isNestedStruct(field.Type().Elem()) {
+			keys := field.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+			for _, key := range keys {
+				// map values are not addressable, walk a copy and set it back if changed, so maps are not written when only read
+				original := field.MapIndex(key)
+				value := reflect.New(field.Type().Elem()).Elem()
+				value.Set(original)
+				if value.Kind() == reflect.Ptr && value.IsNil() {
+					continue
+				}
+
+				target := value
+				for target.Kind() == reflect.Ptr {
+					target = target.Elem()
+				}
+				if err := walkFields(target.Addr().Interface(), fmt.Sprintf("%v[%v]", fieldPath, key), append(fieldNames, fmt.Sprint(key)), fn); err != nil {
+					return err
+				}
+				if !reflect.DeepEqual(value.Interface(), original.Interface()) {
+					field.SetMapIndex(key, value)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetXMLName returns the name of the field in XML files, from the `xml` tag (before the comma) or the lowercased field name
+func GetXMLName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("xml"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
@@ -0,0 +1,109 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Validator is implemented by configs to validate themselves after they're loaded, e.g. check relations between fields,
+// configurations failed to be validated are not applied when reloading
+type Validator interface {
+	Validate() error
+}
+
+// validate checks configurations after they're loaded, failures of non-required rules are collected as warnings into result
+// with graceful degradation
+func (configor *Configor) validate(config interface{}, result *LoadResult) error {
+	err := walkFields(config, "", nil, func(field reflect.Value, fieldStruct reflect.StructField, parent reflect.Value, fieldPath string, fieldNames []string) error {
+		// required_if:"TLSEnabled" or required_if:"Mode=secure", the field is required if the sibling field is true or equals the value
+		if condition := fieldStruct.Tag.Get("required_if"); condition != "" && isBlank(field) {
+			required, err := matchCondition(parent, condition)
+			if err != nil {
+				return configor.fail(result, &PhaseError{Phase: PhaseValidate, Field: fieldPath, Err: fmt.Errorf("invalid required_if tag of %v: %v", fieldPath, err)})
+			}
+
+			if required {
+				return configor.fail(result, &PhaseError{Phase: PhaseValidate, Field: fieldPath, Err: fmt.Errorf("%v is required if %v, but blank", fieldPath, condition)})
+			}
+		}
+
+		if err := checkDurationField(field, fieldStruct, fieldPath); err != nil {
+			if configor.degrade {
+				configor.warn(result, err)
+			} else if err := configor.fail(result, &PhaseError{Phase: PhaseValidate, Field: fieldPath, Err: err}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if validator, ok := config.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return configor.fail(result, &PhaseError{Phase: PhaseValidate, Err: err})
+		}
+	}
+	return nil
+}
+
+// checkDurationField returns a ConfigError if the field is a time.Duration (or a pointer to it) that's not blank and out of the
+// range of `min_duration:"1s"` and `max_duration:"24h"` tags
+func checkDurationField(field reflect.Value, fieldStruct reflect.StructField, fieldPath string) error {
+	if value := reflect.Indirect(field); value.IsValid() && value.Type() == durationType && !isBlank(value) {
+		if err := checkDurationRange(time.Duration(value.Int()), fieldStruct); err != nil {
+			return &ConfigError{Field: fieldPath, Value: time.Duration(value.Int()).String(), Err: err}
+		}
+	}
+	return nil
+}
+
+// checkDurationRange returns an error if the duration is out of the range of `min_duration` and `max_duration` tags
+func checkDurationRange(duration time.Duration, fieldStruct reflect.StructField) error {
+	for _, tag := range []string{"min_duration", "max_duration"} {
+		value := fieldStruct.Tag.Get(tag)
+		if value == "" {
+			continue
+		}
+
+		limit, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %v tag %q: %v", tag, value, err)
+		}
+
+		if tag == "min_duration" && duration < limit {
+			return fmt.Errorf("should be at least %v", limit)
+		}
+		if tag == "max_duration" && duration > limit {
+			return fmt.Errorf("should be at most %v", limit)
+		}
+	}
+	return nil
+}
+
+// matchCondition returns true if the sibling field in condition is true (not blank for non-bool fields),
+// or equals the value if the condition is in the form of Field=value
+func matchCondition(parent reflect.Value, condition string) (bool, error) {
+	name, expected, hasValue := strings.Cut(condition, "=")
+
+	sibling := parent.FieldByName(strings.TrimSpace(name))
+	if !sibling.IsValid() {
+		return false, fmt.Errorf("field %v not found", name)
+	}
+	if !sibling.CanInterface() {
+		return false, fmt.Errorf("field %v is unexported", name)
+	}
+	sibling = reflect.Indirect(sibling)
+
+	switch {
+	case hasValue:
+		return sibling.IsValid() && fmt.Sprint(sibling.Interface()) == expected, nil
+	case sibling.Kind() == reflect.Bool:
+		return sibling.Bool(), nil
+	default:
+		return sibling.IsValid() && !isBlank(sibling), nil
+	}
+}
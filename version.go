@@ -0,0 +1,157 @@
+package configor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FormatVersionKey is the top-level key of configuration files for the version of their format
+const FormatVersionKey = "configor_format_version"
+
+// FormatVersion is the result of checking the format version of a configuration file with WithMinVersion
+type FormatVersion struct {
+	File    string
+	Version string
+	// MigratedTo is the version migrated to with registered migrations, blank if not migrated
+	MigratedTo string
+}
+
+var migrationRegistry = struct {
+	sync.RWMutex
+	migrations map[string]migration
+}{migrations: map[string]migration{}}
+
+type migration struct {
+	to      string
+	migrate func(data map[string]interface{}) error
+}
+
+// RegisterMigration registers the migration of configuration data from version from to version to, migrations are chained
+// until the version is not lower than the version set with WithMinVersion, e.g.
+//
+//	configor.RegisterMigration("1", "2", func(data map[string]interface{}) error {
+//		data["database"] = data["db"]
+//		delete(data, "db")
+//		return nil
+//	})
+func RegisterMigration(from, to string, migrate func(data map[string]interface{}) error) {
+	migrationRegistry.Lock()
+	defer migrationRegistry.Unlock()
+	migrationRegistry.migrations[from] = migration{to: to, migrate: migrate}
+}
+
+// RegisterIntMigration registers the migration of configuration data from integer version fromVersion to toVersion, it's
+// RegisterMigration with versions formatted as strings, so files with `version: 1` are migrated to WithMinVersion("3") by
+// chaining migrations registered in any order, e.g.
+//
+//	configor.RegisterIntMigration(1, 2, migrateV1)
+//	configor.RegisterIntMigration(2, 3, migrateV2)
+//	configor.New(configor.WithMinVersion("3"), configor.WithVersionKey("version")).Load(&Config, "config.yml")
+func RegisterIntMigration(fromVersion, toVersion int, migrate func(data map[string]interface{}) error) {
+	RegisterMigration(strconv.Itoa(fromVersion), strconv.Itoa(toVersion), migrate)
+}
+
+// WithMinVersion check `configor_format_version` (or the key set with WithVersionKey) of configuration files, files with lower versions are migrated with registered
+// migrations, files with higher versions are rejected, files without the version are not checked. Results are in LoadResult.FormatVersions
+func WithMinVersion(version string) Option {
+	return func(configor *Configor) {
+		configor.minVersion = version
+	}
+}
+
+// WithVersionKey set the top-level key of configuration files for their format version checked with WithMinVersion, default
+// is `configor_format_version`, e.g. WithVersionKey("version") for files carrying a `version` field
+func WithVersionKey(key string) Option {
+	return func(configor *Configor) {
+		configor.versionKey = key
+	}
+}
+
+func (configor *Configor) getVersionKey() string {
+	if configor.versionKey != "" {
+		return configor.versionKey
+	}
+	return FormatVersionKey
+}
+
+// checkVersion checks the format version of data, returns the migrated data in json if it's migrated
+func (configor *Configor) checkVersion(result *LoadResult, source Source, config interface{}, data []byte, format string) ([]byte, string, error) {
+	var generic interface{}
+	if err := Decode(&generic, data, format); err != nil {
+		return nil, "", err
+	}
+
+	versionKey := configor.getVersionKey()
+	values, ok := normalizeKeys(generic, nil).(map[string]interface{})
+	if !ok || values[versionKey] == nil {
+		return data, format, nil
+	}
+
+	version := fmt.Sprint(values[versionKey])
+	formatVersion := FormatVersion{File: sourceName(source), Version: version}
+	defer func() { result.FormatVersions = append(result.FormatVersions, formatVersion) }()
+
+	if compareVersions(version, configor.minVersion) > 0 {
+		return nil, "", fmt.Errorf("format version %v is newer than %v", version, configor.minVersion)
+	}
+
+	for compareVersions(version, configor.minVersion) < 0 {
+		migrationRegistry.RLock()
+		m, ok := migrationRegistry.migrations[version]
+		migrationRegistry.RUnlock()
+		if !ok {
+			return nil, "", fmt.Errorf("no migration from format version %v to %v", version, configor.minVersion)
+		}
+		// migrations not increasing the version would be chained forever
+		if compareVersions(m.to, version) <= 0 {
+			return nil, "", fmt.Errorf("invalid migration from format version %v to lower version %v", version, m.to)
+		}
+
+		if err := m.migrate(values); err != nil {
+			return nil, "", fmt.Errorf("failed to migrate format version %v to %v: %v", version, m.to, err)
+		}
+		version = m.to
+		values[versionKey] = version
+	}
+
+	if version == formatVersion.Version {
+		return data, format, nil
+	}
+	formatVersion.MigratedTo = version
+
+	// TOML local times are decoded in local time zone, keys are renamed to json names of fields to decode with json
+	normalizeLocalTimes(values)
+	js, err := json.Marshal(normalizeKeys(values, reflect.TypeOf(config)))
+	return js, "json", err
+}
+
+// compareVersions compares dot-separated versions by numeric parts, e.g. 1.2 < 1.10, non-numeric parts are compared as strings
+func compareVersions(a, b string) int {
+	aParts, bParts := strings.Split(strings.TrimPrefix(a, "v"), "."), strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		aNumber, aErr := strconv.Atoi(aPart)
+		bNumber, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aPart != bPart:
+			return strings.Compare(aPart, bPart)
+		}
+	}
+	return 0
+}
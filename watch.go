@@ -0,0 +1,350 @@
+package configor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WithWatchDebounce set the duration to batch successive file changes into one reload when watching, default is 100ms
+func WithWatchDebounce(debounce time.Duration) Option {
+	return func(configor *Configor) {
+		configor.watchDebounce = debounce
+	}
+}
+
+// WithWatchMinInterval set the minimum interval between two reloads when watching, used to throttle reloads
+func WithWatchMinInterval(interval time.Duration) Option {
+	return func(configor *Configor) {
+		configor.watchMinInterval = interval
+	}
+}
+
+// WithWatchLocker set the lock held when replacing config with reloaded configurations when watching, config is replaced on the
+// watching goroutine, so readers of config should hold the same lock, e.g. RLock of a sync.RWMutex passed as the locker
+func WithWatchLocker(locker sync.Locker) Option {
+	return func(configor *Configor) {
+		configor.watchLocker = locker
+	}
+}
+
+// Watch will load configurations like Load, then watch the files and reload configurations when they're changed
+func Watch(config interface{}, onChange func(config interface{}, err error), files ...string) (io.Closer, error) {
+	return New().Watch(config, onChange, files...)
+}
+
+// Watch will load configurations like Load, then watch the files (including env and example files) and reload configurations
+// when they're changed. Each reload loads into a clone of config as it was before the first load, and replaces config only if
+// it's loaded and validated (including Validate of Validator) successfully, onChange will be called after each reload with the
+// error if failed, config is kept unchanged in that case. config is replaced on the watching goroutine before onChange is called,
+// set WithWatchLocker to replace it with a lock shared with readers of config. Close the returned io.Closer to stop watching
+func (configor *Configor) Watch(config interface{}, onChange func(config interface{}, err error), files ...string) (io.Closer, error) {
+	return configor.WatchSources(config, onChange, fileSources(files)...)
+}
+
+// WatchSources will load configurations from sources like LoadSources, then watch all of them and reload configurations when
+// any of them is changed
+func WatchSources(config interface{}, onChange func(config interface{}, err error), sources ...Source) (io.Closer, error) {
+	return New().WatchSources(config, onChange, sources...)
+}
+
+// WatchSources will load configurations from sources like LoadSources, then watch all of them like Watch. Local files are watched
+// with file system notifications, other sources (e.g. URLs and custom sources) are polled every WithSourcePollInterval and reloaded
+// if hashes of their contents are changed, they're not watched if the interval isn't set. Changes of all sources are debounced into
+// one reload, which reads and merges all sources again
+func (configor *Configor) WatchSources(config interface{}, onChange func(config interface{}, err error), sources ...Source) (io.Closer, error) {
+	// relative files are resolved against the base dir to watch them
+	resolved := make([]Source, len(sources))
+	for i, source := range sources {
+		if file, ok := source.(FileSource); ok {
+			source = FileSource(configor.resolvePath(string(file)))
+		}
+		resolved[i] = source
+	}
+	sources = resolved
+
+	// values set before loading are kept when reloading
+	base, err := Clone(reflect.Indirect(reflect.ValueOf(config)).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configor.LoadSources(config, sources...); err != nil {
+		return nil, err
+	}
+
+	if configor.autoReloadInterval > 0 {
+		return configor.poll(config, base, onChange, sources...)
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	scoped, err := configor.withEnvFile()
+	if err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	// watch directories so files replaced by rename could be detected
+	envs, _ := scoped.envChain(string(scoped.env()))
+	watchedFiles := map[string]bool{}
+	watchedDirs := map[string]bool{}
+	var polledSources []Source
+	for _, source := range sources {
+		// remote configurations and custom sources are polled
+		fileSource, ok := source.(FileSource)
+		if !ok || isURL(string(fileSource)) {
+			polledSources = append(polledSources, source)
+			continue
+		}
+
+		file := string(fileSource)
+		names := []string{file, getFileWithENV(file, "example")}
+		for _, env := range envs {
+			names = append(names, getFileWithENV(file, env))
+		}
+		for _, name := range names {
+			watchedFiles[filepath.Clean(name)] = true
+		}
+
+		if dir := filepath.Dir(file); !watchedDirs[dir] {
+			if err := fsWatcher.Add(dir); err != nil {
+				fsWatcher.Close()
+				return nil, err
+			}
+			watchedDirs[dir] = true
+		}
+	}
+
+	var lastHash string
+	if configor.sourcePollInterval > 0 && len(polledSources) > 0 {
+		if lastHash, err = configor.hashSources(polledSources...); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	} else {
+		polledSources = nil
+	}
+
+	w := &watcher{watcher: fsWatcher, done: make(chan struct{})}
+	go func() {
+		var pollC <-chan time.Time
+		if len(polledSources) > 0 {
+			ticker := time.NewTicker(configor.sourcePollInterval)
+			defer ticker.Stop()
+			pollC = ticker.C
+		}
+
+		var reloadC <-chan time.Time
+		var lastReload time.Time
+		// changes of all sources are batched into one reload
+		scheduleReload := func() {
+			delay := configor.watchDebounce
+			if wait := time.Until(lastReload.Add(configor.watchMinInterval)); wait > delay {
+				delay = wait
+			}
+			reloadC = time.After(delay)
+		}
+
+		for {
+			select {
+			case <-w.done:
+				return
+			case event, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				if watchedFiles[filepath.Clean(event.Name)] {
+					scheduleReload()
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				onChange(config, err)
+			case <-pollC:
+				hash, err := configor.hashSources(polledSources...)
+				if err != nil {
+					onChange(config, err)
+					continue
+				}
+				if hash != lastHash {
+					lastHash = hash
+					scheduleReload()
+				}
+			case <-reloadC:
+				reloadC = nil
+				lastReload = time.Now()
+				onChange(config, configor.reload(config, base, sources...))
+			}
+		}
+	}()
+
+	return w, nil
+}
+
+// reload loads configurations into a clone of base, and replace config with it if loaded and validated successfully
+func (configor *Configor) reload(config interface{}, base interface{}, sources ...Source) error {
+	configValue := reflect.Indirect(reflect.ValueOf(config))
+	clone, err := Clone(base)
+	if err != nil {
+		return err
+	}
+
+	result := reflect.New(configValue.Type())
+	result.Elem().Set(reflect.ValueOf(clone))
+	if _, err := configor.loadConfig(result.Interface(), sources...); err != nil {
+		return err
+	}
+
+	if configor.watchLocker != nil {
+		configor.watchLocker.Lock()
+		defer configor.watchLocker.Unlock()
+	}
+
+	configor.state.mutex.Lock()
+	configValue.Set(result.Elem())
+	configor.state.config, configor.state.keys = config, nil
+	configor.state.mutex.Unlock()
+	return nil
+}
+
+type watcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (w *watcher) Close() (err error) {
+	w.once.Do(func() {
+		close(w.done)
+		if w.watcher != nil {
+			err = w.watcher.Close()
+		}
+	})
+	return err
+}
+
+// WithAutoReload poll files every interval when watching instead of using file system notifications, configurations are
+// reloaded if hashes of files are changed. Useful for file systems without notifications like NFS, remote configurations are also polled
+func WithAutoReload(interval time.Duration) Option {
+	return func(configor *Configor) {
+		configor.autoReloadInterval = interval
+	}
+}
+
+// WithSourcePollInterval set the interval to poll sources other than local files (e.g. URLs and custom sources) when watching
+// with file system notifications, they're reloaded if hashes of their contents are changed. They're not watched by default
+func WithSourcePollInterval(interval time.Duration) Option {
+	return func(configor *Configor) {
+		configor.sourcePollInterval = interval
+	}
+}
+
+// WithAutoReloadFunc set the function to hash files when polling, default is the sha256 of their contents
+func WithAutoReloadFunc(hash func(file string) (string, error)) Option {
+	return func(configor *Configor) {
+		configor.autoReloadFunc = hash
+	}
+}
+
+// poll reloads configurations when hashes of sources are changed, checked every auto reload interval
+func (configor *Configor) poll(config interface{}, base interface{}, onChange func(config interface{}, err error), sources ...Source) (io.Closer, error) {
+	lastHash, err := configor.hashSources(sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &watcher{done: make(chan struct{})}
+	go func() {
+		ticker := time.NewTicker(configor.autoReloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				hash, err := configor.hashSources(sources...)
+				if err != nil {
+					onChange(config, err)
+					continue
+				}
+
+				if hash != lastHash {
+					lastHash = hash
+					onChange(config, configor.reload(config, base, sources...))
+				}
+			}
+		}
+	}()
+	return w, nil
+}
+
+// hashSources returns the combined hash of sources, files and URLs are hashed like hashFiles, other sources are hashed with the
+// sha256 of their contents
+func (configor *Configor) hashSources(sources ...Source) (string, error) {
+	var hashes []string
+	for _, source := range sources {
+		switch source := source.(type) {
+		case FileSource:
+			hash, err := configor.hashFiles(string(source))
+			if err != nil {
+				return "", err
+			}
+			hashes = append(hashes, hash)
+		case URLSource:
+			hash, err := configor.hashFiles(string(source))
+			if err != nil {
+				return "", err
+			}
+			hashes = append(hashes, hash)
+		default:
+			data, _, err := configor.readSource(source)
+			if err != nil {
+				return "", err
+			}
+			sum := sha256.Sum256(data)
+			hashes = append(hashes, sourceName(source)+"="+hex.EncodeToString(sum[:]))
+		}
+	}
+	return strings.Join(hashes, "\n"), nil
+}
+
+// hashFiles returns the combined hash of files to load (including env and example files)
+func (configor *Configor) hashFiles(files ...string) (string, error) {
+	configor, err := configor.withEnvFile()
+	if err != nil {
+		return "", err
+	}
+
+	hashFunc := configor.autoReloadFunc
+	if hashFunc == nil {
+		hashFunc = func(file string) (string, error) {
+			data, _, err := configor.readFile(file)
+			sum := sha256.Sum256(data)
+			return hex.EncodeToString(sum[:]), err
+		}
+	}
+
+	resolvedFiles, _ := configor.getConfigurations(files...)
+	var hashes []string
+	for _, file := range resolvedFiles {
+		hash, err := hashFunc(file)
+		if err != nil {
+			return "", err
+		}
+		hashes = append(hashes, file+"="+hash)
+	}
+	return strings.Join(hashes, "\n"), nil
+}
@@ -0,0 +1,269 @@
+package configor_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jinzhu/configor"
+)
+
+func TestWatchConfiguration(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	ioutil.WriteFile(file, []byte("appname: app1\ndb:\n  password: pass\n"), 0644)
+
+	var result Config
+	changes := make(chan string, 10)
+	watcher, err := configor.New(configor.WithWatchDebounce(50*time.Millisecond)).Watch(&result, func(config interface{}, err error) {
+		if err != nil {
+			changes <- err.Error()
+		} else {
+			changes <- config.(*Config).APPName
+		}
+	}, file)
+	if err != nil {
+		t.Fatalf("No error should happen when watch configurations, but got %v", err)
+	}
+	defer watcher.Close()
+
+	if result.APPName != "app1" {
+		t.Errorf("configurations should be loaded before watching, but got %v", result.APPName)
+	}
+
+	// successive writes should be batched into one reload
+	ioutil.WriteFile(file, []byte("appname: app2\ndb:\n  password: pass\n"), 0644)
+	ioutil.WriteFile(file, []byte("appname: app3\ndb:\n  password: pass\n"), 0644)
+
+	select {
+	case name := <-changes:
+		if name != "app3" {
+			t.Errorf("configurations should be reloaded after file changed, but got %v", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+
+	select {
+	case name := <-changes:
+		t.Errorf("successive file changes should be debounced, but reloaded again with %v", name)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatchConfigurationWithAutoReload(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	ioutil.WriteFile(file, []byte("appname: app1\ndb:\n  password: pass\n"), 0644)
+
+	var result Config
+	changes := make(chan string, 10)
+	watcher, err := configor.New(configor.WithAutoReload(50*time.Millisecond)).Watch(&result, func(config interface{}, err error) {
+		if err != nil {
+			changes <- err.Error()
+		} else {
+			changes <- config.(*Config).APPName
+		}
+	}, file)
+	if err != nil {
+		t.Fatalf("No error should happen when watch configurations, but got %v", err)
+	}
+	defer watcher.Close()
+
+	select {
+	case name := <-changes:
+		t.Errorf("configurations shouldn't be reloaded if files are not changed, but got %v", name)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	ioutil.WriteFile(file, []byte("appname: app2\ndb:\n  password: pass\n"), 0644)
+	select {
+	case name := <-changes:
+		if name != "app2" {
+			t.Errorf("configurations should be reloaded after file changed, but got %v", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+}
+
+type ValidatedConfig struct {
+	APPName string
+	Version string
+	Workers int
+}
+
+func (config *ValidatedConfig) Validate() error {
+	if config.Workers <= 0 {
+		return errors.New("workers should be positive")
+	}
+	return nil
+}
+
+func TestWatchConfigurationWithValidationRollback(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	ioutil.WriteFile(file, []byte("appname: app1\nworkers: 1\n"), 0644)
+
+	result := ValidatedConfig{Version: "v1"}
+	changes := make(chan error, 10)
+	watcher, err := configor.New(configor.WithAutoReload(50*time.Millisecond)).Watch(&result, func(config interface{}, err error) {
+		changes <- err
+	}, file)
+	if err != nil {
+		t.Fatalf("No error should happen when watch configurations, but got %v", err)
+	}
+	defer watcher.Close()
+
+	ioutil.WriteFile(file, []byte("appname: app2\nworkers: 0\n"), 0644)
+	select {
+	case err := <-changes:
+		if err == nil || result.APPName != "app1" {
+			t.Errorf("invalid configurations shouldn't be applied, but got %v, %#v", err, result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+
+	ioutil.WriteFile(file, []byte("appname: app3\nworkers: 2\n"), 0644)
+	select {
+	case err := <-changes:
+		if err != nil || result.APPName != "app3" || result.Workers != 2 || result.Version != "v1" {
+			t.Errorf("valid configurations should be applied with values set before loading kept, but got %v, %#v", err, result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+}
+
+type mutableSource struct {
+	mutex sync.Mutex
+	data  string
+}
+
+func (source *mutableSource) Read() ([]byte, string, error) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	return []byte(source.data), "yml", nil
+}
+
+func (source *mutableSource) set(data string) {
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	source.data = data
+}
+
+func TestWatchSources(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatalf("failed to create temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	ioutil.WriteFile(file, []byte("appname: file\ndb:\n  name: db1\n  password: pass\n"), 0644)
+	memory := &mutableSource{data: "appname: memory1\n"}
+
+	var result Config
+	changes := make(chan Config, 10)
+	loader := configor.New(configor.WithWatchDebounce(50*time.Millisecond), configor.WithSourcePollInterval(50*time.Millisecond))
+	watcher, err := loader.WatchSources(&result, func(config interface{}, err error) {
+		if err != nil {
+			t.Errorf("No error should happen when reload configurations, but got %v", err)
+		}
+		changes <- *config.(*Config)
+	}, memory, configor.FileSource(file))
+	if err != nil {
+		t.Fatalf("No error should happen when watch sources, but got %v", err)
+	}
+	defer watcher.Close()
+
+	if result.APPName != "memory1" || result.DB.Name != "db1" {
+		t.Errorf("sources should be merged before watching, but got %#v", result)
+	}
+
+	memory.set("appname: memory2\n")
+	select {
+	case config := <-changes:
+		if config.APPName != "memory2" || config.DB.Name != "db1" {
+			t.Errorf("all sources should be merged after polled source changed, but got %#v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after polled source changed")
+	}
+
+	ioutil.WriteFile(file, []byte("appname: file\ndb:\n  name: db2\n  password: pass\n"), 0644)
+	select {
+	case config := <-changes:
+		if config.APPName != "memory2" || config.DB.Name != "db2" {
+			t.Errorf("all sources should be merged after file changed, but got %#v", config)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+}
+
+func TestWatchConfigurationWithLocker(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yml")
+	ioutil.WriteFile(file, []byte("appname: app1\ndb:\n  password: pass\n"), 0644)
+
+	var (
+		result Config
+		mutex  sync.RWMutex
+	)
+	changes := make(chan error, 10)
+	watcher, err := configor.New(configor.WithWatchDebounce(10*time.Millisecond), configor.WithWatchLocker(&mutex)).Watch(&result, func(config interface{}, err error) {
+		changes <- err
+	}, file)
+	if err != nil {
+		t.Fatalf("No error should happen when watch configurations, but got %v", err)
+	}
+	defer watcher.Close()
+
+	// readers holding the lock don't race with reloads (checked with -race)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			mutex.RLock()
+			_ = result.APPName
+			mutex.RUnlock()
+		}
+	}()
+
+	ioutil.WriteFile(file, []byte("appname: app2\ndb:\n  password: pass\n"), 0644)
+	select {
+	case err := <-changes:
+		if err != nil {
+			t.Errorf("No error should happen when reload configurations, but got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("configurations should be reloaded after file changed")
+	}
+	<-done
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if result.APPName != "app2" {
+		t.Errorf("config should be replaced after reloading, but got %v", result.APPName)
+	}
+}